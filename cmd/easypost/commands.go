@@ -0,0 +1,372 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/StevenNelson/easypost"
+)
+
+// addressFlags registers flags for the fields of an address, each name
+// starting with prefix, and returns the address they fill in.
+func addressFlags(fs *flag.FlagSet, prefix string) *easypost.Address {
+	addr := &easypost.Address{}
+	fs.StringVar(&addr.Name, prefix+"name", "", "name")
+	fs.StringVar(&addr.Company, prefix+"company", "", "company")
+	fs.StringVar(&addr.Street1, prefix+"street1", "", "first street line")
+	fs.StringVar(&addr.Street2, prefix+"street2", "", "second street line")
+	fs.StringVar(&addr.City, prefix+"city", "", "city")
+	fs.StringVar(&addr.State, prefix+"state", "", "state or province")
+	fs.StringVar(&addr.Zip, prefix+"zip", "", "ZIP or postal code")
+	fs.StringVar(&addr.Country, prefix+"country", "", "country code")
+	fs.StringVar(&addr.Phone, prefix+"phone", "", "phone number")
+	fs.StringVar(&addr.Email, prefix+"email", "", "email address")
+	return addr
+}
+
+func addressCreate(args []string) error {
+	fs := newFlagSet("address create")
+	addr := addressFlags(fs, "")
+	verify := fs.Bool("verify", false, "verify deliverability and report the result")
+	fs.Parse(args)
+
+	var options []easypost.VerifyOption
+	if *verify {
+		options = append(options, easypost.VerifyDelivery)
+	}
+	newAddress, err := easypost.NewAddress(addr, options...)
+	if err != nil {
+		return err
+	}
+	return printAddress(newAddress)
+}
+
+func addressVerify(args []string) error {
+	fs := newFlagSet("address verify")
+	addr := addressFlags(fs, "")
+	id := fs.String("id", "", "verify an existing address instead")
+	fs.Parse(args)
+
+	if *id != "" {
+		existing, err := easypost.RetrieveAddress(*id)
+		if err != nil {
+			return err
+		}
+		if _, err = existing.Verify(); err != nil {
+			return err
+		}
+		return printAddress(existing)
+	}
+	newAddress, err := easypost.CreateAndVerifyAddress(addr)
+	if err != nil {
+		return err
+	}
+	return printAddress(newAddress)
+}
+
+func shipmentCreate(args []string) error {
+	fs := newFlagSet("shipment create")
+	file := fs.String("file", "", "JSON file holding the shipment, - for stdin")
+	from := fs.String("from", "", "id of the from address")
+	to := fs.String("to", "", "id of the to address")
+	parcelId := fs.String("parcel", "", "id of the parcel")
+	length := fs.Float64("length", 0, "parcel length in inches")
+	width := fs.Float64("width", 0, "parcel width in inches")
+	height := fs.Float64("height", 0, "parcel height in inches")
+	weight := fs.Float64("weight", 0, "parcel weight in ounces")
+	predefined := fs.String("package", "", "predefined package, e.g. FlatRateEnvelope")
+	reference := fs.String("reference", "", "reference")
+	fs.Parse(args)
+
+	var shipment easypost.Shipment
+	if *file != "" {
+		if err := readJSON(*file, &shipment); err != nil {
+			return err
+		}
+	} else {
+		if err := required(map[string]string{"from": *from, "to": *to}); err != nil {
+			return err
+		}
+		shipment.FromAddress.Id = *from
+		shipment.ToAddress.Id = *to
+		shipment.Parcel = easypost.Parcel{
+			Id:                *parcelId,
+			Length:            easypost.Inches(*length),
+			Width:             easypost.Inches(*width),
+			Height:            easypost.Inches(*height),
+			Weight:            easypost.Ounces(*weight),
+			PredefinedPackage: *predefined,
+		}
+		shipment.Reference = *reference
+	}
+	newShipment, err := easypost.NewShipment(&shipment)
+	if err != nil {
+		return err
+	}
+	return printShipment(newShipment)
+}
+
+func rates(args []string) error {
+	fs := newFlagSet("rates")
+	shipmentId := fs.String("shipment", "", "shipment id")
+	refresh := fs.Bool("refresh", false, "ask the carriers for fresh rates")
+	fs.Parse(args)
+	if err := required(map[string]string{"shipment": *shipmentId}); err != nil {
+		return err
+	}
+
+	var shipmentRates []easypost.Rate
+	var err error
+	if *refresh {
+		shipmentRates, err = easypost.RerateShipment(*shipmentId)
+	} else {
+		shipmentRates, err = easypost.RetrieveRates(*shipmentId)
+	}
+	if err != nil {
+		return err
+	}
+	return printRates(easypost.RatePolicy{}.Rank(shipmentRates))
+}
+
+func buy(args []string) error {
+	fs := newFlagSet("buy")
+	shipmentId := fs.String("shipment", "", "shipment id")
+	rateId := fs.String("rate", "", "rate id; the cheapest rate if omitted")
+	fs.Parse(args)
+	if err := required(map[string]string{"shipment": *shipmentId}); err != nil {
+		return err
+	}
+
+	if *rateId == "" {
+		shipmentRates, err := easypost.RetrieveRates(*shipmentId)
+		if err != nil {
+			return err
+		}
+		best, _, err := easypost.RatePolicy{}.Select(shipmentRates)
+		if err != nil {
+			return err
+		}
+		*rateId = best.Id
+	}
+	bought, err := easypost.BuyShippingLabel(*shipmentId, *rateId)
+	if err != nil {
+		return err
+	}
+	charged, _ := bought.TotalCharged()
+	return printResult(bought,
+		[]string{"TRACKING", "SERVICE", "PRICE", "CHARGED", "LABEL URL"},
+		[][]string{{bought.TrackingCode, bought.SelectedRate.ServiceName,
+			bought.SelectedRate.Rate.String(), charged.String(),
+			bought.PostageLabel.LabelUrl}})
+}
+
+func refund(args []string) error {
+	fs := newFlagSet("refund")
+	shipmentId := fs.String("shipment", "", "shipment id")
+	fs.Parse(args)
+	if err := required(map[string]string{"shipment": *shipmentId}); err != nil {
+		return err
+	}
+
+	newRefund, err := easypost.NewRefund(*shipmentId)
+	if err != nil {
+		return err
+	}
+	return printResult(newRefund,
+		[]string{"SHIPMENT", "TRACKING", "STATUS"},
+		[][]string{{*shipmentId, newRefund.TrackingCode, string(newRefund.Status)}})
+}
+
+func label(args []string) error {
+	fs := newFlagSet("label")
+	shipmentId := fs.String("shipment", "", "shipment id")
+	output := fs.String("o", "", "file to write; named after the label if omitted")
+	fs.Parse(args)
+	if err := required(map[string]string{"shipment": *shipmentId}); err != nil {
+		return err
+	}
+
+	shipment, err := easypost.RetrieveShipment(*shipmentId)
+	if err != nil {
+		return err
+	}
+	labelUrl := shipment.PostageLabel.LabelUrl
+	if labelUrl == "" {
+		return errors.New("shipment " + *shipmentId + " has no label")
+	}
+	if *output == "" {
+		*output = path.Base(strings.SplitN(labelUrl, "?", 2)[0])
+	}
+	response, err := http.Get(labelUrl)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.New("downloading label: " + response.Status)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, response.Body); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err == nil && !jsonOutput {
+		fmt.Println("wrote " + *output)
+	}
+	return err
+}
+
+func batchCreate(args []string) error {
+	fs := newFlagSet("batch create")
+	file := fs.String("file", "", "JSON file holding an array of shipments, - for stdin")
+	buyLabels := fs.Bool("buy", false, "buy labels using each shipment's first rate")
+	fs.Parse(args)
+	if err := required(map[string]string{"file": *file}); err != nil {
+		return err
+	}
+
+	var shipments []easypost.Shipment
+	if err := readJSON(*file, &shipments); err != nil {
+		return err
+	}
+	newBatch, err := easypost.NewBatch(shipments, *buyLabels)
+	if err != nil {
+		return err
+	}
+	return printResult(newBatch,
+		[]string{"BATCH ID", "SHIPMENTS", "CREATED", "PURCHASED", "FAILED"},
+		[][]string{{newBatch.Id, fmt.Sprint(len(newBatch.Shipments)),
+			fmt.Sprint(newBatch.Status.Created),
+			fmt.Sprint(newBatch.Status.PostagePurchased),
+			fmt.Sprint(newBatch.Status.PostagePurchasedFailed)}})
+}
+
+func importCSV(args []string) error {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "CSV file of shipments, - for stdin")
+	columns := fs.String("columns", "", "JSON file mapping fields to CSV headers")
+	from := fs.String("from", "", "id of the from address for rows without one")
+	fromCountry := fs.String("from-country", "US", "country of the -from address, to detect customs")
+	signer := fs.String("signer", "", "customs signer")
+	carriers := fs.String("carriers", "", "comma-separated carriers to check predefined packages against")
+	metric := fs.Bool("metric", false, "weights in kilograms and lengths in centimeters")
+	useBatch := fs.Bool("batch", false, "create the shipments as one batch")
+	buyLabels := fs.Bool("buy", false, "buy the cheapest rate for each shipment")
+	check := fs.Bool("check", false, "only validate the file")
+	output := fs.String("o", "", "file to write the results CSV to; stdout if omitted")
+	fs.Parse(args)
+	if err := required(map[string]string{"file": *file}); err != nil {
+		return err
+	}
+
+	importer := easypost.CSVImporter{
+		FromAddress:   easypost.Address{Id: *from, Country: *fromCountry},
+		CustomsSigner: *signer,
+		UseBatch:      *useBatch,
+	}
+	if *carriers != "" {
+		importer.Carriers = strings.Split(*carriers, ",")
+	}
+	if *columns != "" {
+		if err := readJSON(*columns, &importer.Columns); err != nil {
+			return err
+		}
+	}
+	if *metric {
+		importer.WeightUnit = easypost.Kilograms
+		importer.LengthUnit = easypost.Centimeters
+	}
+	if *buyLabels {
+		importer.Policy = &easypost.RatePolicy{}
+	}
+
+	var input io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	rows, err := importer.Read(input)
+	if err != nil {
+		return err
+	}
+	var results []easypost.CSVResult
+	if *check {
+		for _, row := range rows {
+			results = append(results, easypost.CSVResult{Line: row.Line,
+				Shipment: row.Shipment, Err: row.Err})
+		}
+	} else if results, err = importer.Create(rows); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	return easypost.WriteCSVResults(out, results)
+}
+
+func scanFormCreate(args []string) error {
+	fs := newFlagSet("scanform create")
+	from := fs.String("from", "", "id of the address the packages are collected from")
+	codes := fs.String("tracking", "", "comma separated tracking codes")
+	fs.Parse(args)
+	if err := required(map[string]string{"from": *from, "tracking": *codes}); err != nil {
+		return err
+	}
+
+	addr, err := easypost.RetrieveAddress(*from)
+	if err != nil {
+		return err
+	}
+	newScanForm, err := easypost.NewScanForm(&easypost.ScanForm{
+		Address:       addr,
+		TrackingCodes: strings.Split(*codes, ","),
+	})
+	if err != nil {
+		return err
+	}
+	return printResult(newScanForm,
+		[]string{"SCAN FORM ID", "CODES", "FORM URL"},
+		[][]string{{newScanForm.Id, fmt.Sprint(len(newScanForm.TrackingCodes)),
+			newScanForm.FormUrl}})
+}
+
+func tracker(args []string) error {
+	fs := newFlagSet("tracker")
+	id := fs.String("id", "", "tracker id")
+	code := fs.String("code", "", "tracking code")
+	carrier := fs.String("carrier", "", "carrier of the tracking code")
+	fs.Parse(args)
+
+	var t easypost.Tracker
+	var err error
+	switch {
+	case *id != "":
+		t, err = easypost.RetrieveTracker(*id)
+	case *code != "":
+		t, err = easypost.NewTracker(*code, *carrier)
+	default:
+		return errors.New("missing required flag -id or -code")
+	}
+	if err != nil {
+		return err
+	}
+	return printTracker(t)
+}
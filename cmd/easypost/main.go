@@ -0,0 +1,192 @@
+// Command easypost performs day to day shipping operations against the
+// EasyPost API from the command line.
+//
+// Usage:
+//
+//	easypost [-key KEY] [-config FILE] [-json] [-live] [-dry-run] COMMAND [SUBCOMMAND] [FLAGS]
+//
+// Commands:
+//
+//	address create    create an address, optionally verifying it
+//	address verify    create and strictly verify an address
+//	shipment create   create a shipment from flags or a JSON file
+//	rates             list the rates of a shipment
+//	buy               buy a label for a shipment
+//	refund            request a refund for a shipment's label
+//	label             download a shipment's label
+//	batch create      create a batch from a JSON file of shipments
+//	import            create shipments from a CSV file
+//	scanform create   create a scan form for tracking codes
+//	tracker           look up tracking for a code or tracker id
+//
+// The API key is read from the -key flag, then the EASYPOST_API_KEY
+// environment variable, then the "api_key" entry of the JSON config file
+// (~/.easypost.json by default). Results are printed as a table, or as JSON
+// with -json. Run a command with -h to see its flags.
+//
+// Purchases with a production key are refused unless -live is given. With
+// -dry-run nothing is sent; each request is printed to stderr instead.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StevenNelson/easypost"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"address create":  {"create an address", addressCreate},
+	"address verify":  {"create and strictly verify an address", addressVerify},
+	"shipment create": {"create a shipment", shipmentCreate},
+	"rates":           {"list the rates of a shipment", rates},
+	"buy":             {"buy a label for a shipment", buy},
+	"refund":          {"request a refund for a shipment's label", refund},
+	"label":           {"download a shipment's label", label},
+	"batch create":    {"create a batch of shipments", batchCreate},
+	"import":          {"create shipments from a CSV file", importCSV},
+	"scanform create": {"create a scan form", scanFormCreate},
+	"tracker":         {"look up tracking", tracker},
+}
+
+// config is the layout of the config file.
+type config struct {
+	ApiKey  string `json:"api_key"`
+	BaseUrl string `json:"base_url"`
+}
+
+// jsonOutput is set by the -json flag.
+var jsonOutput bool
+
+func main() {
+	home, _ := os.UserHomeDir()
+	key := flag.String("key", "", "EasyPost API key")
+	configFile := flag.String("config", filepath.Join(home, ".easypost.json"),
+		"JSON config file holding api_key")
+	flag.BoolVar(&jsonOutput, "json", false, "print results as JSON")
+	live := flag.Bool("live", false, "allow purchases with a production key")
+	dryRun := flag.Bool("dry-run", false, "print requests instead of sending them")
+	flag.Usage = usage
+	flag.Parse()
+
+	name, args := findCommand(flag.Args())
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if *dryRun {
+		easypost.DryRun = &easypost.DryRunRecorder{Log: os.Stderr}
+	}
+	if err := configure(*key, *configFile); err != nil {
+		fatal(err)
+	}
+	easypost.GuardPurchases = true
+	easypost.AllowProductionPurchases = *live
+	if err := cmd.run(args); err != nil {
+		fatal(err)
+	}
+}
+
+// findCommand splits the command name, which may be one or two words, from
+// its arguments.
+func findCommand(args []string) (name string, rest []string) {
+	if len(args) >= 2 {
+		if _, ok := commands[args[0]+" "+args[1]]; ok {
+			return args[0] + " " + args[1], args[2:]
+		}
+	}
+	if len(args) >= 1 {
+		return args[0], args[1:]
+	}
+	return "", nil
+}
+
+func configure(key string, configFile string) error {
+	var conf config
+	if contents, err := ioutil.ReadFile(configFile); err == nil {
+		if err := json.Unmarshal(contents, &conf); err != nil {
+			return fmt.Errorf("reading %s: %v", configFile, err)
+		}
+	}
+	for _, k := range []string{key, os.Getenv("EASYPOST_API_KEY"), conf.ApiKey} {
+		if k != "" {
+			easypost.EasyPostApi["Key"] = k
+			break
+		}
+	}
+	if easypost.EasyPostApi["Key"] == "" && easypost.DryRun == nil {
+		return errors.New("no API key: use -key, EASYPOST_API_KEY or " + configFile)
+	}
+	if conf.BaseUrl != "" {
+		easypost.EasyPostApi["BaseUrl"] = conf.BaseUrl
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: easypost [-key KEY] [-config FILE] [-json] [-live] [-dry-run] COMMAND [FLAGS]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "easypost: "+err.Error())
+	os.Exit(1)
+}
+
+// newFlagSet returns a flag set for a command that exits on bad flags.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("easypost "+name, flag.ExitOnError)
+}
+
+// required returns an error naming the flags that were left empty.
+func required(flags map[string]string) error {
+	var missing []string
+	for name, value := range flags {
+		if value == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return errors.New("missing required flag " + strings.Join(missing, ", "))
+}
+
+// readJSON decodes the JSON file at path into v. A path of "-" reads stdin.
+func readJSON(path string, v interface{}) error {
+	var contents []byte
+	var err error
+	if path == "-" {
+		contents, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		contents, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(contents, v)
+}
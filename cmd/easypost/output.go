@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/StevenNelson/easypost"
+)
+
+// printResult prints v as indented JSON if -json was given, and otherwise
+// as a table with the given header and rows.
+func printResult(v interface{}, header []string, rows [][]string) error {
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func printAddress(addr easypost.Address) error {
+	delivery := ""
+	if len(addr.Verifications.Delivery.Errors) > 0 || addr.Verifications.Delivery.Success {
+		delivery = fmt.Sprint(addr.Verifications.Delivery.Success)
+	}
+	return printResult(addr,
+		[]string{"ID", "NAME", "STREET", "CITY", "STATE", "ZIP", "COUNTRY", "VERIFIED"},
+		[][]string{{addr.Id, addr.Name, strings.TrimSpace(addr.Street1 + " " + addr.Street2),
+			addr.City, addr.State, addr.Zip, addr.Country, delivery}})
+}
+
+func printShipment(shipment easypost.Shipment) error {
+	if jsonOutput {
+		return printResult(shipment, nil, nil)
+	}
+	err := printResult(nil, []string{"ID", "TO", "TRACKING", "LABEL"},
+		[][]string{{shipment.Id, shipment.ToAddress.Name, shipment.TrackingCode,
+			shipment.PostageLabel.LabelUrl}})
+	if err == nil && len(shipment.Rates) > 0 && shipment.PostageLabel.Id == "" {
+		fmt.Println()
+		err = printRates(shipment.Rates)
+	}
+	for _, unrated := range shipment.UnratedCarriers() {
+		fmt.Fprintln(os.Stderr, "no rates from "+unrated.Error())
+	}
+	return err
+}
+
+func printRates(rates []easypost.Rate) error {
+	rows := make([][]string, len(rates))
+	for i, rate := range rates {
+		days := ""
+		if rate.DeliveryDays > 0 {
+			days = fmt.Sprint(rate.DeliveryDays)
+		}
+		rows[i] = []string{rate.Id, rate.Carrier, rate.ServiceName,
+			rate.Rate.String() + " " + rate.Rate.Currency, days}
+	}
+	return printResult(rates,
+		[]string{"RATE ID", "CARRIER", "SERVICE", "PRICE", "DAYS"}, rows)
+}
+
+func printTracker(t easypost.Tracker) error {
+	if jsonOutput {
+		return printResult(t, nil, nil)
+	}
+	fmt.Printf("%s %s: %s\n\n", t.Carrier, t.TrackingCode, t.Status)
+	rows := make([][]string, len(t.TrackingDetails))
+	for i, detail := range t.TrackingDetails {
+		location := strings.Trim(detail.TrackingLocation.City+", "+
+			detail.TrackingLocation.State, ", ")
+		rows[i] = []string{detail.Datetime.Format("2006-01-02 15:04"),
+			string(detail.Status), location, detail.Message}
+	}
+	return printResult(nil, []string{"TIME", "STATUS", "LOCATION", "MESSAGE"}, rows)
+}
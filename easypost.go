@@ -2,12 +2,14 @@ package easypost
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 var EasyPostApi = map[string]string{
@@ -17,17 +19,21 @@ var EasyPostApi = map[string]string{
 	"BaseUrl": "https://api.easypost.com/v2",
 }
 
-func NewAddress(addr *Address) (newAddress Address, err error) {
+// NewAddress creates an address. Any verifications requested are run as the
+// address is created and their results are returned in
+// newAddress.Verifications; a failed strict verification returns an
+// *APIError instead of an address.
+func NewAddress(addr *Address, verify ...VerifyOption) (newAddress Address,
+	err error) {
 	data := url.Values{}
-	data.Set("address[name]", addr.Name)
-	data.Set("address[street1]", addr.Street1)
-	data.Set("address[street2]", addr.Street2)
-	data.Set("address[city]", addr.City)
-	data.Set("address[state]", addr.State)
-	data.Set("address[zip]", addr.Zip)
-	data.Set("address[country]", addr.Country)
-	data.Set("address[phone]", addr.Phone)
-	data.Set("address[email]", addr.Email)
+	encodeAddress(data, "address", addr)
+	for _, option := range verify {
+		if option.Strict {
+			data.Add("verify_strict[]", option.Check)
+		} else {
+			data.Add("verify[]", option.Check)
+		}
+	}
 	response, err := apiCall("/addresses", data)
 	if err == nil {
 		err = handleJson(response, &newAddress)
@@ -35,6 +41,34 @@ func NewAddress(addr *Address) (newAddress Address, err error) {
 	return newAddress, err
 }
 
+// CreateAndVerifyAddress creates an address and verifies that it is
+// deliverable, returning an *APIError describing the problems if it is not.
+func CreateAndVerifyAddress(addr *Address) (newAddress Address, err error) {
+	data := url.Values{}
+	encodeAddress(data, "address", addr)
+	response, err := apiCall("/addresses/create_and_verify", data)
+	var container struct {
+		Address Address
+	}
+	if err == nil {
+		err = handleJson(response, &container)
+	}
+	return container.Address, err
+}
+
+func encodeAddress(data url.Values, prefix string, addr *Address) {
+	data.Set(prefix+"[name]", addr.Name)
+	data.Set(prefix+"[company]", addr.Company)
+	data.Set(prefix+"[street1]", addr.Street1)
+	data.Set(prefix+"[street2]", addr.Street2)
+	data.Set(prefix+"[city]", addr.City)
+	data.Set(prefix+"[state]", addr.State)
+	data.Set(prefix+"[zip]", addr.Zip)
+	data.Set(prefix+"[country]", addr.Country)
+	data.Set(prefix+"[phone]", addr.Phone)
+	data.Set(prefix+"[email]", addr.Email)
+}
+
 func RetrieveAddress(addressId string) (newAddress Address, err error) {
 	response, err := apiCall("/addresses/"+addressId, url.Values{})
 	if err == nil {
@@ -43,23 +77,27 @@ func RetrieveAddress(addressId string) (newAddress Address, err error) {
 	return newAddress, err
 }
 
+// Verify verifies a created address and replaces addr with the verified
+// version returned by the API.
 func (addr *Address) Verify() (message EasyPostMessage, err error) {
 	response, err := apiCall("/addresses/"+addr.Id+"/verify", url.Values{})
-	var verifiedAddress *VerifiedAddress
+	var verifiedAddress VerifiedAddress
 	if err == nil {
 		err = handleJson(response, &verifiedAddress)
-		addr = &verifiedAddress.Address
+	}
+	if err == nil {
+		*addr = verifiedAddress.Address
 		message = verifiedAddress.Message
 	}
 	return message, err
 }
 
 func NewParcel(parc *Parcel) (newParcel Parcel, err error) {
+	if err = parc.Validate(); err != nil {
+		return newParcel, err
+	}
 	data := url.Values{}
-	data.Set("parcel[length]", strconv.FormatFloat(parc.Length, 'f', -1, 64))
-	data.Set("parcel[width]", strconv.FormatFloat(parc.Width, 'f', -1, 64))
-	data.Set("parcel[height]", strconv.FormatFloat(parc.Height, 'f', -1, 64))
-	data.Set("parcel[weight]", strconv.FormatFloat(parc.Weight, 'f', -1, 64))
+	encodeParcel(data, "parcel", parc)
 	response, err := apiCall("/parcels", data)
 	if err == nil {
 
@@ -68,6 +106,32 @@ func NewParcel(parc *Parcel) (newParcel Parcel, err error) {
 	return newParcel, err
 }
 
+// encodeParcel adds the parcel's dimensions to data under prefix, converted
+// to the inches and ounces the API expects. A predefined package's missing
+// dimensions are taken from its nominal size in the catalog; those it
+// doesn't have, such as an envelope's height, are left out.
+func encodeParcel(data url.Values, prefix string, parc *Parcel) {
+	var nominal PredefinedPackage
+	if parc.PredefinedPackage != "" {
+		data.Set(prefix+"[predefined_package]", parc.PredefinedPackage)
+		nominal, _ = predefinedPackageNamed(parc.PredefinedPackage)
+	}
+	dimensions := map[string][2]Length{
+		"length": {parc.Length, nominal.Length},
+		"width":  {parc.Width, nominal.Width},
+		"height": {parc.Height, nominal.Height},
+	}
+	for name, value := range dimensions {
+		if parc.PredefinedPackage != "" && value[0].IsZero() {
+			value[0] = value[1]
+		}
+		if parc.PredefinedPackage == "" || !value[0].IsZero() {
+			data.Set(prefix+"["+name+"]", formatUnits(value[0].Inches()))
+		}
+	}
+	data.Set(prefix+"[weight]", formatUnits(parc.Weight.Ounces()))
+}
+
 func RetrieveParcel(parcelId string) (newParcel Parcel, err error) {
 	response, err := apiCall("/parcels/"+parcelId, url.Values{})
 	if err == nil {
@@ -77,62 +141,75 @@ func RetrieveParcel(parcelId string) (newParcel Parcel, err error) {
 }
 
 func NewShipment(shipment *Shipment) (newShipment Shipment, err error) {
+	return newShipmentContext(context.Background(), shipment)
+}
+
+func newShipmentContext(ctx context.Context, shipment *Shipment) (
+	newShipment Shipment, err error) {
+	if err = shipment.Preflight(); err != nil {
+		return newShipment, err
+	}
 	data := url.Values{}
+	encodeShipment(data, "shipment", shipment)
+	response, err := apiCallContext(ctx, "/shipments", data)
+
+	if err == nil {
+		err = handleJson(response, &newShipment)
+	}
+	return newShipment, err
+}
+
+// encodeShipment adds the fields of a shipment to be created to data.
+func encodeShipment(data url.Values, prefix string, shipment *Shipment) {
 	if len(shipment.ToAddress.Id) > 0 {
-		data.Set("shipment[to_address][id]", shipment.ToAddress.Id)
+		data.Set(prefix+"[to_address][id]", shipment.ToAddress.Id)
 	} else {
-		data.Set("shipment[to_address][name]", shipment.ToAddress.Name)
-		data.Set("shipment[to_address][company]", shipment.ToAddress.Company)
-		data.Set("shipment[to_address][street1]", shipment.ToAddress.Street1)
-		data.Set("shipment[to_address][street2]", shipment.ToAddress.Street2)
-		data.Set("shipment[to_address][city]", shipment.ToAddress.City)
-		data.Set("shipment[to_address][state]", shipment.ToAddress.State)
-		data.Set("shipment[to_address][zip]", shipment.ToAddress.Zip)
-		data.Set("shipment[to_address][country]", shipment.ToAddress.Country)
-		data.Set("shipment[to_address][phone]", shipment.ToAddress.Phone)
-		data.Set("shipment[to_address][phoneNumber]", shipment.ToAddress.Phone)
-		data.Set("shipment[to_address][email]", shipment.ToAddress.Email)
+		data.Set(prefix+"[to_address][name]", shipment.ToAddress.Name)
+		data.Set(prefix+"[to_address][company]", shipment.ToAddress.Company)
+		data.Set(prefix+"[to_address][street1]", shipment.ToAddress.Street1)
+		data.Set(prefix+"[to_address][street2]", shipment.ToAddress.Street2)
+		data.Set(prefix+"[to_address][city]", shipment.ToAddress.City)
+		data.Set(prefix+"[to_address][state]", shipment.ToAddress.State)
+		data.Set(prefix+"[to_address][zip]", shipment.ToAddress.Zip)
+		data.Set(prefix+"[to_address][country]", shipment.ToAddress.Country)
+		data.Set(prefix+"[to_address][phone]", shipment.ToAddress.Phone)
+		data.Set(prefix+"[to_address][phoneNumber]", shipment.ToAddress.Phone)
+		data.Set(prefix+"[to_address][email]", shipment.ToAddress.Email)
 	}
 	if len(shipment.FromAddress.Id) > 0 {
-		data.Set("shipment[from_address][id]", shipment.FromAddress.Id)
+		data.Set(prefix+"[from_address][id]", shipment.FromAddress.Id)
 	} else {
-		data.Set("shipment[from_address][name]", shipment.FromAddress.Name)
-		data.Set("shipment[from_address][company]", shipment.FromAddress.Company)
-		data.Set("shipment[from_address][street1]", shipment.FromAddress.Street1)
-		data.Set("shipment[from_address][street2]", shipment.FromAddress.Street2)
-		data.Set("shipment[from_address][city]", shipment.FromAddress.City)
-		data.Set("shipment[from_address][state]", shipment.FromAddress.State)
-		data.Set("shipment[from_address][zip]", shipment.FromAddress.Zip)
-		data.Set("shipment[from_address][country]", shipment.FromAddress.Country)
-		data.Set("shipment[from_address][phone]", shipment.FromAddress.Phone)
-		data.Set("shipment[from_address][phoneNumber]", shipment.FromAddress.Phone)
-		data.Set("shipment[from_address][email]", shipment.FromAddress.Email)
+		data.Set(prefix+"[from_address][name]", shipment.FromAddress.Name)
+		data.Set(prefix+"[from_address][company]", shipment.FromAddress.Company)
+		data.Set(prefix+"[from_address][street1]", shipment.FromAddress.Street1)
+		data.Set(prefix+"[from_address][street2]", shipment.FromAddress.Street2)
+		data.Set(prefix+"[from_address][city]", shipment.FromAddress.City)
+		data.Set(prefix+"[from_address][state]", shipment.FromAddress.State)
+		data.Set(prefix+"[from_address][zip]", shipment.FromAddress.Zip)
+		data.Set(prefix+"[from_address][country]", shipment.FromAddress.Country)
+		data.Set(prefix+"[from_address][phone]", shipment.FromAddress.Phone)
+		data.Set(prefix+"[from_address][phoneNumber]", shipment.FromAddress.Phone)
+		data.Set(prefix+"[from_address][email]", shipment.FromAddress.Email)
 	}
 	if len(shipment.Parcel.Id) > 0 {
-		data.Set("shipment[parcel][id]", shipment.Parcel.Id)
+		data.Set(prefix+"[parcel][id]", shipment.Parcel.Id)
 	} else {
-		data.Set("shipment[parcel][length]",
-			strconv.FormatFloat(shipment.Parcel.Length, 'f', -1, 64))
-		data.Set("shipment[parcel][width]",
-			strconv.FormatFloat(shipment.Parcel.Width, 'f', -1, 64))
-		data.Set("shipment[parcel][height]",
-			strconv.FormatFloat(shipment.Parcel.Height, 'f', -1, 64))
-		data.Set("shipment[parcel][weight]",
-			strconv.FormatFloat(shipment.Parcel.Weight, 'f', -1, 64))
+		encodeParcel(data, prefix+"[parcel]", &shipment.Parcel)
 	}
 	if shipment.CustomsInfo.Id != "" {
-		data.Set("shipment[customs_info][id]", shipment.CustomsInfo.Id)
+		data.Set(prefix+"[customs_info][id]", shipment.CustomsInfo.Id)
+	} else if len(shipment.CustomsInfo.CustomsItems) > 0 {
+		encodeCustomsInfo(data, prefix+"[customs_info]", &shipment.CustomsInfo)
 	}
-	response, err := apiCall("/shipments", data)
-
-	if err == nil {
-		err = handleJson(response, &newShipment)
+	if !shipment.Insurance.IsZero() {
+		data.Set(prefix+"[insurance]", shipment.Insurance.String())
 	}
-	for key, v := range newShipment.Rates {
-		newShipment.Rates[key].ServiceName = rateMap[v.Service]
-		newShipment.Rates[key].RateFloat, _ = strconv.ParseFloat(v.Rate, 64)
+	if shipment.IsReturn {
+		data.Set(prefix+"[is_return]", "true")
+	}
+	if shipment.Reference != "" {
+		data.Set(prefix+"[reference]", shipment.Reference)
 	}
-	return newShipment, err
 }
 
 func RetrieveShipment(shipmentId string) (newShipment Shipment, err error) {
@@ -144,52 +221,128 @@ func RetrieveShipment(shipmentId string) (newShipment Shipment, err error) {
 }
 
 func RetrieveRates(shipmentId string) (rates []Rate, err error) {
-	response, err := apiCall("/shipments/"+shipmentId+"/rates", url.Values{})
+	return retrieveRatesContext(context.Background(), shipmentId)
+}
+
+func retrieveRatesContext(ctx context.Context, shipmentId string) (rates []Rate,
+	err error) {
+	response, err := apiCallContext(ctx, "/shipments/"+shipmentId+"/rates", url.Values{})
 	var container Shipment // Dummy shipping object to unmarshal rates into
 	if err == nil {
 		err = handleJson(response, &container)
 	}
+	return container.Rates, err // Return only the rates array.
+}
+
+// RerateShipment asks the carriers for fresh rates for a shipment, replacing
+// the stored ones. Only the given carrier accounts are rated, or every
+// account if none are given.
+func RerateShipment(shipmentId string, carrierAccounts ...string) (rates []Rate,
+	err error) {
+	data := url.Values{}
+	for _, account := range carrierAccounts {
+		data.Add("carrier_accounts[]", account)
+	}
+	response, err := apiRequest("POST", "/shipments/"+shipmentId+"/rerate", data)
+	var container Shipment
+	if err == nil {
+		err = handleJson(response, &container)
+	}
+	return container.Rates, err
+}
 
-	for _, v := range container.Rates {
-		v.ServiceName = rateMap[v.Service]
+// QuoteRates returns rates for a shipment without creating it, from the
+// API's beta stateless rating endpoint. The rates have no Id and can't be
+// bought; create the shipment to buy a label.
+func QuoteRates(shipment *Shipment, carrierAccounts ...string) (rates []Rate,
+	err error) {
+	if err = shipment.Preflight(); err != nil {
+		return rates, err
 	}
-	return container.Rates, err // Return only the rates array.
+	data := url.Values{}
+	encodeShipment(data, "shipment", shipment)
+	for _, account := range carrierAccounts {
+		data.Add("shipment[carrier_accounts][][id]", account)
+	}
+	response, err := apiCall("/beta/rates", data)
+	var container Shipment
+	if err == nil {
+		err = handleJson(response, &container)
+	}
+	return container.Rates, err
+}
+
+// RetrieveSmartRates returns the shipment's rates along with each service's
+// historical time in transit between the shipment's addresses.
+func RetrieveSmartRates(shipmentId string) (smartRates []SmartRate, err error) {
+	response, err := apiCall("/shipments/"+shipmentId+"/smartrate", url.Values{})
+	var container struct {
+		Result []SmartRate
+	}
+	if err == nil {
+		err = handleJson(response, &container)
+	}
+	return container.Result, err
 }
 
 /*
  * Buy shipment
  */
-func BuyShippingLabel(shipmentId string, rateId string) (postageLabel PostageLabel,
+
+// BuyShippingLabel buys the rate for the shipment and returns the purchased
+// shipment, with its PostageLabel, SelectedRate, TrackingCode and Fees.
+func BuyShippingLabel(shipmentId string, rateId string) (shipment Shipment,
 	err error) {
+	return buyShippingLabelContext(context.Background(), shipmentId, rateId)
+}
+
+func buyShippingLabelContext(ctx context.Context, shipmentId string,
+	rateId string) (shipment Shipment, err error) {
 	data := url.Values{}
 	data.Set("rate[id]", rateId)
-	response, err := apiCall("/shipments/"+shipmentId+"/buy", data)
-	var temp = map[string]json.RawMessage{}
+	response, err := apiCallContext(ctx, "/shipments/"+shipmentId+"/buy", data)
+	if err == nil {
+		err = handleJson(response, &shipment)
+	}
+	return shipment, err
+}
 
+// InsureShipment buys insurance for the shipment's declared value, which is
+// always in US dollars, and returns the updated shipment.
+func InsureShipment(shipmentId string, amount Money) (shipment Shipment,
+	err error) {
+	if amount.Currency != "" && !strings.EqualFold(amount.Currency, "USD") {
+		return shipment, FieldError{Field: "amount",
+			Message: "insurance must be in USD, not " + amount.Currency}
+	}
+	data := url.Values{}
+	data.Set("amount", amount.String())
+	response, err := apiCall("/shipments/"+shipmentId+"/insure", data)
 	if err == nil {
-		err = handleJson(response, &temp)
-		if err != nil {
-			fmt.Println("Error parsing response from label purchase" +
-				err.Error() + ": " + string(response))
-		}
-		err = handleJson(temp["postage_label"], &postageLabel)
-		if err != nil {
-			fmt.Println("Error parsing postage label for " + shipmentId)
-			fmt.Println(err.Error() + ": " + string(response))
-		}
+		err = handleJson(response, &shipment)
+	}
+	return shipment, err
+}
+
+// ConvertLabel asks for the shipment's label in another format, one of the
+// Label constants, and returns the shipment with the new label URL filled in.
+func ConvertLabel(shipmentId string, format string) (shipment Shipment,
+	err error) {
+	response, err := apiCall("/shipments/"+shipmentId+"/label?file_format="+
+		url.QueryEscape(format), url.Values{})
+	if err == nil {
+		err = handleJson(response, &shipment)
 	}
-	return postageLabel, err
+	return shipment, err
 }
 
 func NewCustomsItem(customsItem *CustomsItem) (newCustomsItem CustomsItem,
 	err error) {
+	if err = customsItem.Validate(); err != nil {
+		return newCustomsItem, err
+	}
 	data := url.Values{}
-	data.Set("customs_item[description]", customsItem.Description)
-	data.Set("customs_item[quantity]", strconv.FormatFloat(customsItem.Quantity, 'f', -1, 64))
-	data.Set("customs_item[value]", customsItem.Value)
-	data.Set("customs_item[weight]", strconv.FormatFloat(customsItem.Weight, 'f', -1, 64))
-	data.Set("customs_item[hs_tariff_number]", customsItem.HsTariffNumber)
-	data.Set("customs_item[origin_country]", customsItem.OriginCountry)
+	encodeCustomsItem(data, "customs_item", customsItem)
 	response, err := apiCall("/customs_items", data)
 	if err == nil {
 		err = handleJson(response, &newCustomsItem)
@@ -197,6 +350,24 @@ func NewCustomsItem(customsItem *CustomsItem) (newCustomsItem CustomsItem,
 	return newCustomsItem, err
 }
 
+func encodeCustomsItem(data url.Values, prefix string, item *CustomsItem) {
+	if item.Id != "" {
+		data.Set(prefix+"[id]", item.Id)
+	}
+	data.Set(prefix+"[description]", item.Description)
+	if item.Code != "" {
+		data.Set(prefix+"[code]", item.Code)
+	}
+	data.Set(prefix+"[quantity]", strconv.FormatFloat(item.Quantity, 'f', -1, 64))
+	data.Set(prefix+"[value]", item.Value.String())
+	if item.Value.Currency != "" {
+		data.Set(prefix+"[currency]", item.Value.Currency)
+	}
+	data.Set(prefix+"[weight]", formatUnits(item.Weight.Ounces()))
+	data.Set(prefix+"[hs_tariff_number]", item.HsTariffNumber)
+	data.Set(prefix+"[origin_country]", item.OriginCountry)
+}
+
 func RetrieveCustomsItem(customsItemId string) (newCustomsItem CustomsItem,
 	err error) {
 	response, err := apiCall("/customs_items/"+customsItemId, url.Values{})
@@ -208,26 +379,11 @@ func RetrieveCustomsItem(customsItemId string) (newCustomsItem CustomsItem,
 
 func NewCustomsInfo(customsInfo *CustomsInfo) (newCustomsInfo CustomsInfo,
 	err error) {
-	data := url.Values{}
-	data.Set("customs_info[customs_certify]", strconv.FormatBool(customsInfo.CustomsCertify))
-	data.Set("customs_info[customs_signer]", customsInfo.CustomsSigner)
-	data.Set("customs_info[contents_type]", customsInfo.ContentsType)
-	data.Set("customs_info[contents_explanation]", customsInfo.ContentsExplanation)
-	data.Set("customs_info[restriction_type]", customsInfo.RestrictionType)
-	data.Set("customs_info[eel_pfc]", customsInfo.EelPfc)
-
-	for index, val := range customsInfo.CustomsItems {
-		prefix := "customs_info[customs_items][" + strconv.Itoa(index) + "]"
-		data.Set(prefix+"[id]", val.Id)
-		data.Set(prefix+"[description]", val.Description)
-		data.Set(prefix+"[quantity]", strconv.FormatFloat(val.Quantity, 'f', -1, 64))
-		// Docs said value is numeric, implementation returns a string
-		//data.Set( prefix + "[value]", strconv.FormatFloat(val.Value, 'f', -1, 64))
-		data.Set(prefix+"[value]", val.Value)
-		data.Set(prefix+"[weight]", strconv.FormatFloat(val.Weight, 'f', -1, 64))
-		data.Set(prefix+"[hs_tariff_number]", val.HsTariffNumber)
-		data.Set(prefix+"[origin_country]", val.OriginCountry)
+	if err = customsInfo.Validate(); err != nil {
+		return newCustomsInfo, err
 	}
+	data := url.Values{}
+	encodeCustomsInfo(data, "customs_info", customsInfo)
 	response, err := apiCall("/customs_infos", data)
 	if err == nil {
 		err = handleJson(response, &newCustomsInfo)
@@ -235,6 +391,22 @@ func NewCustomsInfo(customsInfo *CustomsInfo) (newCustomsInfo CustomsInfo,
 	return newCustomsInfo, err
 }
 
+func encodeCustomsInfo(data url.Values, prefix string, customsInfo *CustomsInfo) {
+	data.Set(prefix+"[customs_certify]", strconv.FormatBool(customsInfo.CustomsCertify))
+	data.Set(prefix+"[customs_signer]", customsInfo.CustomsSigner)
+	data.Set(prefix+"[contents_type]", string(customsInfo.ContentsType))
+	data.Set(prefix+"[contents_explanation]", customsInfo.ContentsExplanation)
+	data.Set(prefix+"[restriction_type]", string(customsInfo.RestrictionType))
+	data.Set(prefix+"[restriction_comments]", customsInfo.RestrictionComments)
+	data.Set(prefix+"[non_delivery_option]", string(customsInfo.NonDeliveryOption))
+	data.Set(prefix+"[eel_pfc]", customsInfo.EelPfc)
+
+	for index := range customsInfo.CustomsItems {
+		itemPrefix := prefix + "[customs_items][" + strconv.Itoa(index) + "]"
+		encodeCustomsItem(data, itemPrefix, &customsInfo.CustomsItems[index])
+	}
+}
+
 func RetrieveCustomsInfo(customsInfoId string) (newCustomsInfo CustomsInfo,
 	err error) {
 	response, err := apiCall("/customs_infos/"+customsInfoId, url.Values{})
@@ -245,13 +417,19 @@ func RetrieveCustomsInfo(customsInfoId string) (newCustomsInfo CustomsInfo,
 }
 
 func NewRefund(shipmentId string) (newRefund Refund, err error) {
-	response, err := apiCall("/shipments/"+shipmentId+"/refund", url.Values{})
+	response, err := refundShipment(shipmentId)
 	if err == nil {
 		err = handleJson(response, &newRefund)
 	}
 	return newRefund, err
 }
 
+// refundShipment requests a refund of the shipment's label, for NewRefund
+// and Shipment.Refund.
+func refundShipment(shipmentId string) ([]byte, error) {
+	return apiRequest("POST", "/shipments/"+shipmentId+"/refund", nil)
+}
+
 // NewRefundOutsideEasyPost will not likely handle more than one tracking code
 // at a time.
 func NewRefundOutsideEasyPost(carrier string,
@@ -274,40 +452,36 @@ func RetrieveRefund(refundId string) (newRefund Refund, err error) {
 	return newRefund, err
 }
 
+// NewBatch creates a batch of shipments, each checked with Preflight first;
+// problems are reported with the shipment's index, e.g.
+// "shipments[2].customs_info". With createAndBuy each shipment must have a
+// rate, and is bought with the carrier and service of its first one.
 func NewBatch(shipments []Shipment, createAndBuy bool) (newBatch Batch, err error) {
+	var errs ValidationErrors
+	for index := range shipments {
+		shipment := &shipments[index]
+		prefix := "shipments[" + strconv.Itoa(index) + "]."
+		var carriers []string
+		if createAndBuy && len(shipment.Rates) > 0 {
+			carriers = []string{shipment.Rates[0].Carrier}
+		}
+		if err := shipment.Preflight(carriers...); err != nil {
+			errs = append(errs, withPrefix(err, prefix).(ValidationErrors)...)
+		}
+		if createAndBuy && len(shipment.Rates) == 0 {
+			errs = append(errs, FieldError{Field: prefix + "rates",
+				Message: "must hold the rate to buy"})
+		}
+	}
+	if err = errs.err(); err != nil {
+		return newBatch, err
+	}
 	data := url.Values{}
 
-	for index, val := range shipments {
-		prefix := "batch[shipment][" + strconv.Itoa(index) + "]"
-		data.Set(prefix+"[from_address][name]", val.FromAddress.Name)
-		data.Set(prefix+"[from_address][company]", val.FromAddress.Company)
-		data.Set(prefix+"[from_address][street1]", val.FromAddress.Street1)
-		data.Set(prefix+"[from_address][street2]", val.FromAddress.Street2)
-		data.Set(prefix+"[from_address][city]", val.FromAddress.City)
-		data.Set(prefix+"[from_address][state]", val.FromAddress.State)
-		data.Set(prefix+"[from_address][zip]", val.FromAddress.Zip)
-		data.Set(prefix+"[from_address][country]", val.FromAddress.Country)
-		data.Set(prefix+"[from_address][phone]", val.FromAddress.Phone)
-		data.Set(prefix+"[from_address][email]", val.FromAddress.Email)
-		data.Set(prefix+"[to_address][name]", val.ToAddress.Name)
-		data.Set(prefix+"[to_address][company]", val.ToAddress.Company)
-		data.Set(prefix+"[to_address][street1]", val.ToAddress.Street1)
-		data.Set(prefix+"[to_address][street2]", val.ToAddress.Street2)
-		data.Set(prefix+"[to_address][city]", val.ToAddress.City)
-		data.Set(prefix+"[to_address][state]", val.ToAddress.State)
-		data.Set(prefix+"[to_address][zip]", val.ToAddress.Zip)
-		data.Set(prefix+"[to_address][country]", val.ToAddress.Country)
-		data.Set(prefix+"[to_address][phone]", val.ToAddress.Phone)
-		data.Set(prefix+"[to_address][email]", val.ToAddress.Email)
-		data.Set(prefix+"[parcel][length]",
-			strconv.FormatFloat(val.Parcel.Length, 'f', -1, 64))
-		data.Set(prefix+"[parcel][width]",
-			strconv.FormatFloat(val.Parcel.Width, 'f', -1, 64))
-		data.Set(prefix+"[parcel][height]",
-			strconv.FormatFloat(val.Parcel.Height, 'f', -1, 64))
-		data.Set(prefix+"[parcel][weight]",
-			strconv.FormatFloat(val.Parcel.Weight, 'f', -1, 64))
-		data.Set(prefix+"[reference]", val.Reference)
+	for index := range shipments {
+		val := &shipments[index]
+		prefix := "batch[shipments][" + strconv.Itoa(index) + "]"
+		encodeShipment(data, prefix, val)
 		if createAndBuy {
 			data.Set(prefix+"[carrier]", val.Rates[0].Carrier)
 			data.Set(prefix+"[service]", val.Rates[0].Service)
@@ -326,6 +500,25 @@ func NewBatch(shipments []Shipment, createAndBuy bool) (newBatch Batch, err erro
 	return newBatch, err
 }
 
+func RetrieveBatch(batchId string) (newBatch Batch, err error) {
+	response, err := apiCall("/batches/"+batchId, url.Values{})
+	if err == nil {
+		err = handleJson(response, &newBatch)
+	}
+	return newBatch, err
+}
+
+// BuyBatch buys labels for every shipment in the batch using the rate each
+// was created with. Purchasing is asynchronous; retrieve the batch to see its
+// progress.
+func BuyBatch(batchId string) (newBatch Batch, err error) {
+	response, err := apiRequest("POST", "/batches/"+batchId+"/buy", nil)
+	if err == nil {
+		err = handleJson(response, &newBatch)
+	}
+	return newBatch, err
+}
+
 // RetrieveBatchLabel requests the url of a batch label once all postage for
 // the batch has been purchased. The label url will not be available until all
 // the shipments are in the "postage_purchased" status. labelType can be one of
@@ -361,9 +554,9 @@ func addOrRemoveShipmentsToBatch(batchId string, shipmentIds []string,
 	}
 	var response []byte
 	if removeShipment {
-		response, err = apiCall("/batches/"+batchId+"remove_shipments", data)
+		response, err = apiCall("/batches/"+batchId+"/remove_shipments", data)
 	} else {
-		response, err = apiCall("/batches/"+batchId+"add_shipments", data)
+		response, err = apiCall("/batches/"+batchId+"/add_shipments", data)
 	}
 	if err == nil {
 		err = handleJson(response, &newBatch)
@@ -372,11 +565,11 @@ func addOrRemoveShipmentsToBatch(batchId string, shipmentIds []string,
 }
 
 func NewScanForm(scanForm *ScanForm) (newScanForm ScanForm, err error) {
-	trackingCodes := ""
-	for _, val := range scanForm.TrackingCodes {
-		trackingCodes += val + ","
+	if len(scanForm.TrackingCodes) == 0 {
+		return newScanForm, ValidationErrors{{Field: "tracking_codes",
+			Message: "at least one tracking code is required"}}
 	}
-	trackingCodes = trackingCodes[:len(trackingCodes)-1]
+	trackingCodes := strings.Join(scanForm.TrackingCodes, ",")
 
 	data := url.Values{}
 	data.Set("scan_form[from_address][name]", scanForm.Address.Name)
@@ -396,8 +589,6 @@ func NewScanForm(scanForm *ScanForm) (newScanForm ScanForm, err error) {
 		err = handleJson(response, &newScanForm)
 	}
 	return newScanForm, err
-	err = handleJson(response, &newScanForm)
-	return newScanForm, err
 }
 
 func RetrieveScanForm(scanFormId string) (newScanForm ScanForm, err error) {
@@ -408,6 +599,31 @@ func RetrieveScanForm(scanFormId string) (newScanForm ScanForm, err error) {
 	return newScanForm, err
 }
 
+// NewTracker starts tracking a package that wasn't necessarily shipped
+// through EasyPost. carrier may be left empty for the API to guess it from
+// the tracking code.
+func NewTracker(trackingCode string, carrier string) (newTracker Tracker,
+	err error) {
+	data := url.Values{}
+	data.Set("tracker[tracking_code]", trackingCode)
+	if carrier != "" {
+		data.Set("tracker[carrier]", carrier)
+	}
+	response, err := apiCall("/trackers", data)
+	if err == nil {
+		err = handleJson(response, &newTracker)
+	}
+	return newTracker, err
+}
+
+func RetrieveTracker(trackerId string) (newTracker Tracker, err error) {
+	response, err := apiCall("/trackers/"+trackerId, url.Values{})
+	if err == nil {
+		err = handleJson(response, &newTracker)
+	}
+	return newTracker, err
+}
+
 // handleJson provides a thin wrapper around the json.Unmarshal func to catch
 // errors returned by the EasyPost API. If an error is returned from the API,
 // it is converted into a proper Go error, and returned.
@@ -421,38 +637,141 @@ func handleJson(response []byte, target interface{}) error {
 	    parsed := rawResponse.(map[string]interface{})
 		  return errors.New(parsed["error"].(string))
 	  }*/
+	if err = responseError(response); err != nil {
+		return err
+	}
 	err = json.Unmarshal(response, &target)
 	if err != nil {
 		fmt.Println(err.Error() + ":  " + string(response))
 	}
-	epm, ok := target.(EasyPostResponse)
-	if err == nil && ok && epm.Error != "" {
-		err = errors.New(epm.Error)
-	}
 	return err
 }
 
+// responseError returns the error held in an API response, if there is one.
+// Errors are normally an object with a code, message and field errors, but
+// older endpoints reply with just a message string.
+func responseError(response []byte) error {
+	var envelope struct {
+		Error json.RawMessage
+	}
+	if json.Unmarshal(response, &envelope) != nil || len(envelope.Error) == 0 {
+		return nil
+	}
+	apiErr := &APIError{}
+	if json.Unmarshal(envelope.Error, apiErr) != nil {
+		json.Unmarshal(envelope.Error, &apiErr.Message)
+	}
+	if apiErr.Code == "" && apiErr.Message == "" {
+		return nil
+	}
+	return apiErr
+}
+
 func apiCall(path string, data url.Values) (response []byte, err error) {
+	return apiCallContext(context.Background(), path, data)
+}
+
+// apiCallContext is apiCall with a context that cancels the request.
+func apiCallContext(ctx context.Context, path string, data url.Values) (
+	response []byte, err error) {
+	requestMethod := "POST"
+	if len(data) == 0 {
+		requestMethod = "GET"
+	}
+	return apiRequestContext(ctx, requestMethod, path, data)
+}
+
+// apiRequest is apiCall for endpoints that need a particular method, such as
+// a POST without any parameters.
+func apiRequest(requestMethod string, path string, data url.Values) (
+	response []byte, err error) {
+	return apiRequestContext(context.Background(), requestMethod, path, data)
+}
+
+// apiRequestContext is apiRequest with a context. Once ctx is done no
+// further attempt is made, a wait for Limiter is cut short, and a request
+// still waiting for its response is abandoned, each with ctx.Err(). A
+// response that has already arrived is kept, so that e.g. a label that was
+// bought is not reported as cancelled.
+func apiRequestContext(ctx context.Context, requestMethod string, path string,
+	data url.Values) (response []byte, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if DryRun != nil {
+		return DryRun.record(requestMethod, path, data), nil
+	}
 	if EasyPostApi["Key"] == "" {
 		return nil, errors.New("please specify an API key")
 	}
+	if err = guardPurchase(requestMethod, path); err != nil {
+		return nil, err
+	}
 	/*
 	 * Construct Message Body
 	 */
 	var postBody bytes.Buffer
 	for key, val := range data {
-		postBody.WriteString(key + "=" + url.QueryEscape(val[0]) + "&")
+		for _, v := range val {
+			postBody.WriteString(key + "=" + url.QueryEscape(v) + "&")
+		}
 	}
-	requestMethod := "POST"
 	if len(data) > 0 {
 		postBody.Truncate(postBody.Len() - 1)
-	} else {
-		requestMethod = "GET"
 	}
 	mBody := postBody.Bytes()
-	endpointUrl := EasyPostApi["BaseUrl"] + path
-	request, err := http.NewRequest(requestMethod, endpointUrl,
+	endpointUrl := apiUrl(path)
+	for attempt := 0; ; attempt++ {
+		if Limiter != nil {
+			if err = Limiter.Wait(ctx, path); err != nil {
+				return nil, err
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		httpResponse, result, err := sendRequest(ctx, requestMethod, endpointUrl,
+			mBody)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if Limiter != nil {
+			throttled := Limiter.Observe(path, httpResponse.StatusCode,
+				httpResponse.Header.Get("Retry-After"))
+			if throttled && attempt < Limiter.MaxRetries {
+				continue
+			}
+		}
+		if httpResponse.StatusCode == http.StatusTooManyRequests &&
+			responseError(result) == nil {
+			return result, &APIError{Code: "RATE_LIMITED",
+				Message: "too many requests"}
+		}
+		return result, nil
+	}
+}
+
+// apiUrl returns the URL of an endpoint. Beta endpoints sit beside the
+// versioned API rather than under it, e.g. https://api.easypost.com/beta/rates.
+func apiUrl(path string) string {
+	base := EasyPostApi["BaseUrl"]
+	if strings.HasPrefix(path, "/beta/") {
+		return strings.TrimSuffix(base, "/v2") + path
+	}
+	return base + path
+}
+
+// sendRequest makes one request to the API and reads the whole response.
+func sendRequest(ctx context.Context, requestMethod string, endpointUrl string,
+	mBody []byte) (httpResponse *http.Response, result []byte, err error) {
+	request, err := http.NewRequestWithContext(ctx, requestMethod, endpointUrl,
 		bytes.NewReader(mBody))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	/*
 	 * Set Header information
@@ -469,25 +788,22 @@ func apiCall(path string, data url.Values) (response []byte, err error) {
 	 */
 	client := &http.Client{}
 	httpResponse, httpErr := client.Do(request)
-	var result []byte
 
 	/*
 	 * Handle (or don't) any errors that occured in the transport
 	 */
 	if httpErr != nil {
-		fmt.Println(postBody.Bytes())
-		fmt.Println(httpErr.Error())
-		return nil, httpErr
+		if ctx.Err() == nil { // Cancelled requests aren't worth reporting
+			fmt.Println(mBody)
+			fmt.Println(httpErr.Error())
+		}
+		return nil, nil, httpErr
 	} else if httpResponse.Body == nil {
-		fmt.Println(postBody.Bytes())
-		return nil, errors.New("response from api is empty")
-	} else {
-		responseBuffer := new(bytes.Buffer)
-		responseBuffer.ReadFrom(httpResponse.Body)
-		result = responseBuffer.Bytes()
-	}
-	if httpResponse.Body != nil {
-		httpResponse.Body.Close()
+		fmt.Println(mBody)
+		return nil, nil, errors.New("response from api is empty")
 	}
-	return result, nil
+	responseBuffer := new(bytes.Buffer)
+	responseBuffer.ReadFrom(httpResponse.Body)
+	httpResponse.Body.Close()
+	return httpResponse, responseBuffer.Bytes(), nil
 }
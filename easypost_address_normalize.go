@@ -0,0 +1,566 @@
+package easypost
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Normalize tidies addr in place without contacting the API and reports the
+// problems that remain. Whitespace is trimmed from every field, country names
+// are replaced by their ISO 3166 alpha-2 codes, US state and Canadian
+// province names by their postal abbreviations, and US ZIP and Canadian
+// postal codes are put in their standard form. An empty country is treated as
+// the United States, as the API does, but is left empty.
+//
+// The returned error, if any, is a ValidationErrors naming each field that is
+// missing or malformed. Normalize can't tell whether an address exists; use
+// NewAddress with a VerifyOption for that.
+func (addr *Address) Normalize() error {
+	fields := []*string{&addr.Name, &addr.Company, &addr.Street1,
+		&addr.Street2, &addr.City, &addr.State, &addr.Zip, &addr.Country,
+		&addr.Email, &addr.Phone}
+	for _, field := range fields {
+		*field = strings.TrimSpace(*field)
+	}
+
+	var errs ValidationErrors
+	country := "US"
+	if addr.Country != "" {
+		if code, ok := NormalizeCountry(addr.Country); ok {
+			addr.Country = code
+			country = code
+		} else {
+			errs = append(errs, FieldError{Field: "country",
+				Message: "unknown country " + addr.Country})
+			country = ""
+		}
+	}
+	if addr.Street1 == "" {
+		errs = append(errs, FieldError{Field: "street1", Message: "is required"})
+	}
+	if addr.City == "" {
+		errs = append(errs, FieldError{Field: "city", Message: "is required"})
+	}
+
+	var subdivisions map[string]string
+	switch country {
+	case "US":
+		subdivisions = usStates
+	case "CA":
+		subdivisions = caProvinces
+	}
+	if subdivisions != nil {
+		if code, ok := normalizeSubdivision(subdivisions, addr.State); ok {
+			addr.State = code
+		} else if addr.State == "" {
+			errs = append(errs, FieldError{Field: "state", Message: "is required"})
+		} else {
+			errs = append(errs, FieldError{Field: "state",
+				Message: "unknown state or province " + addr.State})
+		}
+	}
+
+	if pattern, ok := postalCodePatterns[country]; ok {
+		zip := normalizePostalCode(country, addr.Zip)
+		if zip == "" {
+			errs = append(errs, FieldError{Field: "zip", Message: "is required"})
+		} else if !pattern.MatchString(zip) {
+			errs = append(errs, FieldError{Field: "zip",
+				Message: "is not a valid postal code for " + country})
+		} else {
+			addr.Zip = zip
+		}
+	}
+	return errs.err()
+}
+
+// NormalizeCountry returns the ISO 3166 alpha-2 code for a country given by
+// code or by name, e.g. "us", "USA" and "United States" all give "US".
+func NormalizeCountry(country string) (code string, ok bool) {
+	upper := strings.ToUpper(strings.TrimSpace(country))
+	if _, ok := countries[upper]; ok {
+		return upper, true
+	}
+	key := nameKey(country)
+	if code, ok := countryAliases[key]; ok {
+		return code, true
+	}
+	for code, name := range countries {
+		if nameKey(name) == key {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// CountryName returns the English name of the country with the given ISO
+// 3166 alpha-2 code.
+func CountryName(code string) (name string, ok bool) {
+	name, ok = countries[strings.ToUpper(code)]
+	return name, ok
+}
+
+func normalizeSubdivision(subdivisions map[string]string, state string) (
+	string, bool) {
+	upper := strings.ToUpper(state)
+	for _, code := range subdivisions {
+		if code == upper {
+			return code, true
+		}
+	}
+	code, ok := subdivisions[nameKey(state)]
+	return code, ok
+}
+
+// normalizePostalCode puts US and Canadian postal codes in their standard
+// form and upper-cases all others. Codes that can't be tidied are returned
+// as they are so that the caller's pattern rejects them.
+func normalizePostalCode(country string, zip string) string {
+	zip = strings.ToUpper(strings.TrimSpace(zip))
+	compact := strings.Join(strings.Fields(zip), "")
+	switch country {
+	case "US":
+		compact = strings.Replace(compact, "-", "", -1)
+		if len(compact) == 9 {
+			return compact[:5] + "-" + compact[5:]
+		}
+		if len(compact) == 5 {
+			return compact
+		}
+	case "CA":
+		if len(compact) == 6 {
+			return compact[:3] + " " + compact[3:]
+		}
+	}
+	return zip
+}
+
+// nameKey reduces a place name to a form that ignores case, punctuation,
+// accents and the common ways "and" and "saint" are written.
+func nameKey(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("&", " and ", ".", " ", ",", " ", "(", " ",
+		")", " ", "'", "", "-", " ", "å", "a", "ç", "c",
+		"é", "e", "ô", "o").Replace(name)
+	words := strings.Fields(name)
+	for i, word := range words {
+		if word == "saint" {
+			words[i] = "st"
+		}
+	}
+	if len(words) > 0 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[A-Z] \d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IE": regexp.MustCompile(`^[A-Z]\d[\dW] ?[A-Z\d]{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"NZ": regexp.MustCompile(`^\d{4}$`),
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"CN": regexp.MustCompile(`^\d{6}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+}
+
+// usStates maps the name keys of US states, districts, territories and
+// military "states" to their postal abbreviations.
+var usStates = map[string]string{
+	"alabama":                        "AL",
+	"alaska":                         "AK",
+	"arizona":                        "AZ",
+	"arkansas":                       "AR",
+	"california":                     "CA",
+	"colorado":                       "CO",
+	"connecticut":                    "CT",
+	"delaware":                       "DE",
+	"district of columbia":           "DC",
+	"washington dc":                  "DC",
+	"florida":                        "FL",
+	"georgia":                        "GA",
+	"hawaii":                         "HI",
+	"idaho":                          "ID",
+	"illinois":                       "IL",
+	"indiana":                        "IN",
+	"iowa":                           "IA",
+	"kansas":                         "KS",
+	"kentucky":                       "KY",
+	"louisiana":                      "LA",
+	"maine":                          "ME",
+	"maryland":                       "MD",
+	"massachusetts":                  "MA",
+	"michigan":                       "MI",
+	"minnesota":                      "MN",
+	"mississippi":                    "MS",
+	"missouri":                       "MO",
+	"montana":                        "MT",
+	"nebraska":                       "NE",
+	"nevada":                         "NV",
+	"new hampshire":                  "NH",
+	"new jersey":                     "NJ",
+	"new mexico":                     "NM",
+	"new york":                       "NY",
+	"north carolina":                 "NC",
+	"north dakota":                   "ND",
+	"ohio":                           "OH",
+	"oklahoma":                       "OK",
+	"oregon":                         "OR",
+	"pennsylvania":                   "PA",
+	"rhode island":                   "RI",
+	"south carolina":                 "SC",
+	"south dakota":                   "SD",
+	"tennessee":                      "TN",
+	"texas":                          "TX",
+	"utah":                           "UT",
+	"vermont":                        "VT",
+	"virginia":                       "VA",
+	"washington":                     "WA",
+	"west virginia":                  "WV",
+	"wisconsin":                      "WI",
+	"wyoming":                        "WY",
+	"american samoa":                 "AS",
+	"guam":                           "GU",
+	"northern mariana islands":       "MP",
+	"puerto rico":                    "PR",
+	"virgin islands":                 "VI",
+	"us virgin islands":              "VI",
+	"federated states of micronesia": "FM",
+	"marshall islands":               "MH",
+	"palau":                          "PW",
+	"armed forces americas":          "AA",
+	"armed forces europe":            "AE",
+	"armed forces pacific":           "AP",
+}
+
+// caProvinces maps the name keys of Canadian provinces and territories to
+// their postal abbreviations.
+var caProvinces = map[string]string{
+	"alberta":                   "AB",
+	"british columbia":          "BC",
+	"manitoba":                  "MB",
+	"new brunswick":             "NB",
+	"newfoundland and labrador": "NL",
+	"newfoundland":              "NL",
+	"nova scotia":               "NS",
+	"northwest territories":     "NT",
+	"nunavut":                   "NU",
+	"ontario":                   "ON",
+	"prince edward island":      "PE",
+	"quebec":                    "QC",
+	"saskatchewan":              "SK",
+	"yukon":                     "YT",
+}
+
+// countryAliases maps the name keys of common alternative country names and
+// alpha-3 codes to alpha-2 codes. Official names are matched from countries.
+var countryAliases = map[string]string{
+	"usa":                      "US",
+	"u s a":                    "US",
+	"u s":                      "US",
+	"america":                  "US",
+	"united states of america": "US",
+	"can":                      "CA",
+	"mex":                      "MX",
+	"uk":                       "GB",
+	"u k":                      "GB",
+	"gbr":                      "GB",
+	"great britain":            "GB",
+	"britain":                  "GB",
+	"england":                  "GB",
+	"scotland":                 "GB",
+	"wales":                    "GB",
+	"northern ireland":         "GB",
+	"deu":                      "DE",
+	"deutschland":              "DE",
+	"fra":                      "FR",
+	"espana":                   "ES",
+	"aus":                      "AU",
+	"jpn":                      "JP",
+	"chn":                      "CN",
+	"holland":                  "NL",
+	"korea":                    "KR",
+	"republic of korea":        "KR",
+	"russian federation":       "RU",
+	"czech republic":           "CZ",
+	"ivory coast":              "CI",
+	"burma":                    "MM",
+	"swaziland":                "SZ",
+	"east timor":               "TL",
+	"vatican":                  "VA",
+	"holy see":                 "VA",
+	"macau":                    "MO",
+	"viet nam":                 "VN",
+	"cape verde":               "CV",
+	"cabo verde":               "CV",
+	"macedonia":                "MK",
+}
+
+// countries maps ISO 3166 alpha-2 codes to English short names.
+var countries = map[string]string{
+	"AD": "Andorra",
+	"AE": "United Arab Emirates",
+	"AF": "Afghanistan",
+	"AG": "Antigua and Barbuda",
+	"AI": "Anguilla",
+	"AL": "Albania",
+	"AM": "Armenia",
+	"AO": "Angola",
+	"AQ": "Antarctica",
+	"AR": "Argentina",
+	"AS": "American Samoa",
+	"AT": "Austria",
+	"AU": "Australia",
+	"AW": "Aruba",
+	"AX": "Aland Islands",
+	"AZ": "Azerbaijan",
+	"BA": "Bosnia and Herzegovina",
+	"BB": "Barbados",
+	"BD": "Bangladesh",
+	"BE": "Belgium",
+	"BF": "Burkina Faso",
+	"BG": "Bulgaria",
+	"BH": "Bahrain",
+	"BI": "Burundi",
+	"BJ": "Benin",
+	"BL": "Saint Barthelemy",
+	"BM": "Bermuda",
+	"BN": "Brunei",
+	"BO": "Bolivia",
+	"BQ": "Bonaire, Sint Eustatius and Saba",
+	"BR": "Brazil",
+	"BS": "Bahamas",
+	"BT": "Bhutan",
+	"BV": "Bouvet Island",
+	"BW": "Botswana",
+	"BY": "Belarus",
+	"BZ": "Belize",
+	"CA": "Canada",
+	"CC": "Cocos (Keeling) Islands",
+	"CD": "Democratic Republic of the Congo",
+	"CF": "Central African Republic",
+	"CG": "Republic of the Congo",
+	"CH": "Switzerland",
+	"CI": "Cote d'Ivoire",
+	"CK": "Cook Islands",
+	"CL": "Chile",
+	"CM": "Cameroon",
+	"CN": "China",
+	"CO": "Colombia",
+	"CR": "Costa Rica",
+	"CU": "Cuba",
+	"CV": "Cape Verde",
+	"CW": "Curacao",
+	"CX": "Christmas Island",
+	"CY": "Cyprus",
+	"CZ": "Czechia",
+	"DE": "Germany",
+	"DJ": "Djibouti",
+	"DK": "Denmark",
+	"DM": "Dominica",
+	"DO": "Dominican Republic",
+	"DZ": "Algeria",
+	"EC": "Ecuador",
+	"EE": "Estonia",
+	"EG": "Egypt",
+	"EH": "Western Sahara",
+	"ER": "Eritrea",
+	"ES": "Spain",
+	"ET": "Ethiopia",
+	"FI": "Finland",
+	"FJ": "Fiji",
+	"FK": "Falkland Islands",
+	"FM": "Micronesia",
+	"FO": "Faroe Islands",
+	"FR": "France",
+	"GA": "Gabon",
+	"GB": "United Kingdom",
+	"GD": "Grenada",
+	"GE": "Georgia",
+	"GF": "French Guiana",
+	"GG": "Guernsey",
+	"GH": "Ghana",
+	"GI": "Gibraltar",
+	"GL": "Greenland",
+	"GM": "Gambia",
+	"GN": "Guinea",
+	"GP": "Guadeloupe",
+	"GQ": "Equatorial Guinea",
+	"GR": "Greece",
+	"GS": "South Georgia and the South Sandwich Islands",
+	"GT": "Guatemala",
+	"GU": "Guam",
+	"GW": "Guinea-Bissau",
+	"GY": "Guyana",
+	"HK": "Hong Kong",
+	"HM": "Heard Island and McDonald Islands",
+	"HN": "Honduras",
+	"HR": "Croatia",
+	"HT": "Haiti",
+	"HU": "Hungary",
+	"ID": "Indonesia",
+	"IE": "Ireland",
+	"IL": "Israel",
+	"IM": "Isle of Man",
+	"IN": "India",
+	"IO": "British Indian Ocean Territory",
+	"IQ": "Iraq",
+	"IR": "Iran",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JE": "Jersey",
+	"JM": "Jamaica",
+	"JO": "Jordan",
+	"JP": "Japan",
+	"KE": "Kenya",
+	"KG": "Kyrgyzstan",
+	"KH": "Cambodia",
+	"KI": "Kiribati",
+	"KM": "Comoros",
+	"KN": "Saint Kitts and Nevis",
+	"KP": "North Korea",
+	"KR": "South Korea",
+	"KW": "Kuwait",
+	"KY": "Cayman Islands",
+	"KZ": "Kazakhstan",
+	"LA": "Laos",
+	"LB": "Lebanon",
+	"LC": "Saint Lucia",
+	"LI": "Liechtenstein",
+	"LK": "Sri Lanka",
+	"LR": "Liberia",
+	"LS": "Lesotho",
+	"LT": "Lithuania",
+	"LU": "Luxembourg",
+	"LV": "Latvia",
+	"LY": "Libya",
+	"MA": "Morocco",
+	"MC": "Monaco",
+	"MD": "Moldova",
+	"ME": "Montenegro",
+	"MF": "Saint Martin",
+	"MG": "Madagascar",
+	"MH": "Marshall Islands",
+	"MK": "North Macedonia",
+	"ML": "Mali",
+	"MM": "Myanmar",
+	"MN": "Mongolia",
+	"MO": "Macao",
+	"MP": "Northern Mariana Islands",
+	"MQ": "Martinique",
+	"MR": "Mauritania",
+	"MS": "Montserrat",
+	"MT": "Malta",
+	"MU": "Mauritius",
+	"MV": "Maldives",
+	"MW": "Malawi",
+	"MX": "Mexico",
+	"MY": "Malaysia",
+	"MZ": "Mozambique",
+	"NA": "Namibia",
+	"NC": "New Caledonia",
+	"NE": "Niger",
+	"NF": "Norfolk Island",
+	"NG": "Nigeria",
+	"NI": "Nicaragua",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NP": "Nepal",
+	"NR": "Nauru",
+	"NU": "Niue",
+	"NZ": "New Zealand",
+	"OM": "Oman",
+	"PA": "Panama",
+	"PE": "Peru",
+	"PF": "French Polynesia",
+	"PG": "Papua New Guinea",
+	"PH": "Philippines",
+	"PK": "Pakistan",
+	"PL": "Poland",
+	"PM": "Saint Pierre and Miquelon",
+	"PN": "Pitcairn",
+	"PR": "Puerto Rico",
+	"PS": "Palestine",
+	"PT": "Portugal",
+	"PW": "Palau",
+	"PY": "Paraguay",
+	"QA": "Qatar",
+	"RE": "Reunion",
+	"RO": "Romania",
+	"RS": "Serbia",
+	"RU": "Russia",
+	"RW": "Rwanda",
+	"SA": "Saudi Arabia",
+	"SB": "Solomon Islands",
+	"SC": "Seychelles",
+	"SD": "Sudan",
+	"SE": "Sweden",
+	"SG": "Singapore",
+	"SH": "Saint Helena",
+	"SI": "Slovenia",
+	"SJ": "Svalbard and Jan Mayen",
+	"SK": "Slovakia",
+	"SL": "Sierra Leone",
+	"SM": "San Marino",
+	"SN": "Senegal",
+	"SO": "Somalia",
+	"SR": "Suriname",
+	"SS": "South Sudan",
+	"ST": "Sao Tome and Principe",
+	"SV": "El Salvador",
+	"SX": "Sint Maarten",
+	"SY": "Syria",
+	"SZ": "Eswatini",
+	"TC": "Turks and Caicos Islands",
+	"TD": "Chad",
+	"TF": "French Southern Territories",
+	"TG": "Togo",
+	"TH": "Thailand",
+	"TJ": "Tajikistan",
+	"TK": "Tokelau",
+	"TL": "Timor-Leste",
+	"TM": "Turkmenistan",
+	"TN": "Tunisia",
+	"TO": "Tonga",
+	"TR": "Turkey",
+	"TT": "Trinidad and Tobago",
+	"TV": "Tuvalu",
+	"TW": "Taiwan",
+	"TZ": "Tanzania",
+	"UA": "Ukraine",
+	"UG": "Uganda",
+	"UM": "United States Minor Outlying Islands",
+	"US": "United States",
+	"UY": "Uruguay",
+	"UZ": "Uzbekistan",
+	"VA": "Vatican City",
+	"VC": "Saint Vincent and the Grenadines",
+	"VE": "Venezuela",
+	"VG": "British Virgin Islands",
+	"VI": "US Virgin Islands",
+	"VN": "Vietnam",
+	"VU": "Vanuatu",
+	"WF": "Wallis and Futuna",
+	"WS": "Samoa",
+	"YE": "Yemen",
+	"YT": "Mayotte",
+	"ZA": "South Africa",
+	"ZM": "Zambia",
+	"ZW": "Zimbabwe",
+}
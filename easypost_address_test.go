@@ -0,0 +1,88 @@
+package easypost
+
+import (
+	"testing"
+)
+
+func TestAddressVerificationsJSON(t *testing.T) {
+	var addr Address
+	err := handleJson([]byte(`{"id": "adr_1", "residential": true,
+		"verifications": {
+			"delivery": {"success": false, "errors": [{"code": "E.HOUSE_NUMBER.MISSING",
+				"field": "street1", "message": "House number is missing",
+				"suggestion": null}], "details": {}},
+			"zip4": {"success": true, "errors": [],
+				"details": {"latitude": 35.08, "longitude": -92.44,
+					"time_zone": "America/Chicago"}}}}`), &addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := addr.Verifications.Delivery
+	if !addr.Residential || delivery.Success || len(delivery.Errors) != 1 ||
+		delivery.Errors[0].Code != "E.HOUSE_NUMBER.MISSING" ||
+		addr.Verifications.Zip4.Details.TimeZone != "America/Chicago" {
+		t.Fatalf("decoded %+v", addr)
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	err := handleJson([]byte(`{"error": {"code": "ADDRESS.VERIFY.FAILURE",
+		"message": "Unable to verify address.",
+		"errors": [{"field": "address", "message": "Address not found"}]}}`),
+		&Address{})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != "ADDRESS.VERIFY.FAILURE" || len(apiErr.Errors) != 1 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	err = handleJson([]byte(`{"error": "Not found"}`), &Address{})
+	if err == nil || err.Error() != "Not found" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err = handleJson([]byte(`{"id": "adr_1", "error": null}`), &Address{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddressNormalize(t *testing.T) {
+	addr := Address{
+		Street1: " 1111 Main St ",
+		City:    "Conway",
+		State:   "arkansas",
+		Zip:     "72032 1234",
+		Country: "United States of America",
+	}
+	if err := addr.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if addr.Street1 != "1111 Main St" || addr.State != "AR" ||
+		addr.Zip != "72032-1234" || addr.Country != "US" {
+		t.Fatalf("normalized to %+v", addr)
+	}
+
+	addr = Address{Street1: "1 Rue", City: "Montréal", State: "Québec",
+		Zip: "h2x1y4", Country: "canada"}
+	if err := addr.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if addr.State != "QC" || addr.Zip != "H2X 1Y4" || addr.Country != "CA" {
+		t.Fatalf("normalized to %+v", addr)
+	}
+
+	code, ok := NormalizeCountry("Trinidad & Tobago")
+	if !ok || code != "TT" {
+		t.Fatal("Trinidad & Tobago gave " + code)
+	}
+
+	addr = Address{Street1: "1 Main", State: "Ontaria", Zip: "1234",
+		Country: "Atlantis"}
+	errs, ok := addr.Normalize().(ValidationErrors)
+	if !ok || len(errs) != 2 || errs[0].Field != "country" ||
+		errs[1].Field != "city" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	addr = Address{Street1: "1 Main", City: "Conway", State: "ZZ", Zip: "7203"}
+	errs, ok = addr.Normalize().(ValidationErrors)
+	if !ok || len(errs) != 2 || errs[0].Field != "state" || errs[1].Field != "zip" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,347 @@
+package easypost
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVImporter reads shipments from a CSV export, one shipment per row, and
+// creates them. Each row may carry a single customs item, which is enough
+// for most order exports; rows without a customs description get none.
+//
+// Fields are named as in the API, prefixed by the object they belong to:
+//
+//	to_address.name ... to_address.email (likewise from_address.*)
+//	parcel.length, parcel.width, parcel.height, parcel.weight,
+//	parcel.predefined_package
+//	customs_item.description, customs_item.code, customs_item.quantity,
+//	customs_item.value, customs_item.weight, customs_item.hs_tariff_number,
+//	customs_item.origin_country
+//	reference
+//
+// Columns maps a field to the CSV header that holds it. Fields not in
+// Columns are read from a header of the same name if there is one.
+type CSVImporter struct {
+	Columns map[string]string
+	// FromAddress is used for rows without from_address columns. If it
+	// is given by Id it is not validated, but its Country must be set so
+	// that rows needing customs can be told apart.
+	FromAddress Address
+	// WeightUnit and LengthUnit convert the numbers in the file. They
+	// default to Ounces and Inches.
+	WeightUnit func(float64) Weight
+	LengthUnit func(float64) Length
+	// Currency of customs values, USD if empty.
+	Currency string
+	// CustomsSigner signs the customs declarations of rows with customs
+	// items.
+	CustomsSigner string
+	// UseBatch creates the shipments with one NewBatch call instead of a
+	// NewShipment call per row. Every row then needs a unique reference,
+	// which is how the batch's shipments are matched back to rows.
+	UseBatch bool
+	// Policy, if set, buys a label for each shipment created with
+	// NewShipment. It is ignored when UseBatch is set.
+	Policy *RatePolicy
+	// Carriers, if set, are the carriers the shipments will be rated with;
+	// rows whose predefined package one of them doesn't offer fail.
+	Carriers []string
+}
+
+// CSVRow is one row read from the file. Line is its line number, counting
+// the header as line 1. Err holds any local validation failure, in which
+// case the row is not created.
+type CSVRow struct {
+	Line     int
+	Shipment Shipment
+	Err      error
+}
+
+// CSVRowError reports a problem with one row of the file.
+type CSVRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *CSVRowError) Error() string {
+	return "row " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// CSVResult is the outcome of creating the shipment for one row.
+type CSVResult struct {
+	Line     int
+	Shipment Shipment
+	Err      error
+}
+
+var csvAddressFields = []string{"name", "company", "street1", "street2",
+	"city", "state", "zip", "country", "phone", "email"}
+
+// Read parses and validates every row of the file without contacting the
+// API. Rows that fail validation or can't be parsed, such as rows with the
+// wrong number of fields, are returned with Err set so that they can all be
+// reported at once; the error returned is only for unreadable input.
+func (im *CSVImporter) Read(r io.Reader) (rows []CSVRow, err error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if parseErr, ok := err.(*csv.ParseError); ok {
+			rows = append(rows, CSVRow{Line: parseErr.StartLine,
+				Err: &CSVRowError{Line: parseErr.StartLine, Err: parseErr.Err}})
+			continue
+		} else if err != nil {
+			return rows, err
+		}
+		// Quoted fields may span lines, so the line is the one the record
+		// starts on rather than a count of records.
+		line, _ := reader.FieldPos(0)
+		row := CSVRow{Line: line}
+		if len(record) != len(header) {
+			row.Err = &CSVRowError{Line: line, Err: errors.New("has " +
+				strconv.Itoa(len(record)) + " fields, want " +
+				strconv.Itoa(len(header)))}
+			rows = append(rows, row)
+			continue
+		}
+		row.Shipment, row.Err = im.parseRow(func(field string) (string, bool) {
+			column := field
+			if mapped, ok := im.Columns[field]; ok {
+				column = mapped
+			}
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return "", false
+			}
+			return strings.TrimSpace(record[i]), true
+		})
+		if row.Err != nil {
+			row.Err = &CSVRowError{Line: line, Err: row.Err}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (im *CSVImporter) parseRow(get func(field string) (string, bool)) (
+	shipment Shipment, err error) {
+	var errs ValidationErrors
+	number := func(field string) float64 {
+		value, _ := get(field)
+		if value == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, FieldError{Field: field, Message: "is not a number"})
+		}
+		return f
+	}
+	weightUnit, lengthUnit := im.WeightUnit, im.LengthUnit
+	if weightUnit == nil {
+		weightUnit = Ounces
+	}
+	if lengthUnit == nil {
+		lengthUnit = Inches
+	}
+
+	shipment.FromAddress = im.FromAddress
+	for _, prefix := range []string{"to_address", "from_address"} {
+		addr := &shipment.ToAddress
+		if prefix == "from_address" {
+			addr = &shipment.FromAddress
+		}
+		fields := []*string{&addr.Name, &addr.Company, &addr.Street1,
+			&addr.Street2, &addr.City, &addr.State, &addr.Zip, &addr.Country,
+			&addr.Phone, &addr.Email}
+		for i, name := range csvAddressFields {
+			if value, ok := get(prefix + "." + name); ok {
+				*fields[i] = value
+				// The row's own address replaces FromAddress.
+				addr.Id = ""
+			}
+		}
+		if addr.Id != "" {
+			if !addressKnown(addr) {
+				errs = append(errs, FieldError{Field: prefix + ".country",
+					Message: "must be given with the address Id to tell whether customs is required"})
+			}
+			continue
+		}
+		if err := addr.Normalize(); err != nil {
+			errs = append(errs, withPrefix(err, prefix+".").(ValidationErrors)...)
+		}
+	}
+
+	shipment.Parcel.PredefinedPackage, _ = get("parcel.predefined_package")
+	shipment.Parcel.Length = lengthUnit(number("parcel.length"))
+	shipment.Parcel.Width = lengthUnit(number("parcel.width"))
+	shipment.Parcel.Height = lengthUnit(number("parcel.height"))
+	shipment.Parcel.Weight = weightUnit(number("parcel.weight"))
+	shipment.Reference, _ = get("reference")
+	shipment.Carriers = im.Carriers
+
+	if description, _ := get("customs_item.description"); description != "" {
+		currency := im.Currency
+		if currency == "" {
+			currency = "USD"
+		}
+		item := CustomsItem{
+			Description: description,
+			Quantity:    number("customs_item.quantity"),
+			Value:       Money{Currency: currency},
+			Currency:    currency,
+			Weight:      weightUnit(number("customs_item.weight")),
+		}
+		if value, _ := get("customs_item.value"); value != "" {
+			total, err := ParseMoney(value, currency)
+			if err != nil {
+				errs = append(errs, FieldError{Field: "customs_item.value",
+					Message: "is not an amount"})
+			}
+			item.Value = total
+		}
+		item.Code, _ = get("customs_item.code")
+		item.HsTariffNumber, _ = get("customs_item.hs_tariff_number")
+		origin, _ := get("customs_item.origin_country")
+		if code, ok := NormalizeCountry(origin); ok {
+			origin = code
+		}
+		item.OriginCountry = origin
+		shipment.CustomsInfo = CustomsInfo{
+			ContentsType:      ContentsMerchandise,
+			RestrictionType:   RestrictionNone,
+			NonDeliveryOption: NonDeliveryReturn,
+			CustomsCertify:    true,
+			CustomsSigner:     im.CustomsSigner,
+			CustomsItems:      []CustomsItem{item},
+		}
+		if err := shipment.CustomsInfo.Validate(); err != nil {
+			errs = append(errs, withPrefix(err, "customs_info.").(ValidationErrors)...)
+		}
+	}
+
+	if err := shipment.Preflight(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	return shipment, errs.err()
+}
+
+// Create creates the shipments for the rows that passed validation and
+// returns a result for every row, in file order. Rows that failed
+// validation keep their error. With UseBatch, a failure to create the batch
+// is returned as err; otherwise each row's API error is in its result.
+//
+// Batches are created asynchronously, so with UseBatch the shipments the
+// API hasn't created yet are returned with only their BatchId filled in;
+// retrieve the batch later to get them.
+func (im *CSVImporter) Create(rows []CSVRow) (results []CSVResult, err error) {
+	results = make([]CSVResult, len(rows))
+	var valid []int
+	for i, row := range rows {
+		results[i] = CSVResult{Line: row.Line, Shipment: row.Shipment, Err: row.Err}
+		if row.Err == nil {
+			valid = append(valid, i)
+		}
+	}
+	if im.UseBatch {
+		return results, im.createBatch(rows, results, valid)
+	}
+	for _, i := range valid {
+		var shipment Shipment
+		if im.Policy != nil {
+			shipment, err = CreateAndBuyBest(&rows[i].Shipment, *im.Policy)
+		} else {
+			shipment, err = NewShipment(&rows[i].Shipment)
+		}
+		if err != nil {
+			err = &CSVRowError{Line: rows[i].Line, Err: err}
+		}
+		if shipment.Id == "" {
+			// Not created, so keep the row's shipment to report its reference.
+			shipment = rows[i].Shipment
+		}
+		results[i] = CSVResult{Line: rows[i].Line, Shipment: shipment, Err: err}
+	}
+	return results, nil
+}
+
+// createBatch creates the valid rows as one batch and fills in their
+// results, matching the batch's shipments to rows by reference.
+func (im *CSVImporter) createBatch(rows []CSVRow, results []CSVResult,
+	valid []int) error {
+	byReference := map[string]int{}
+	var shipments []Shipment
+	for _, i := range valid {
+		reference := rows[i].Shipment.Reference
+		if _, duplicate := byReference[reference]; reference == "" || duplicate {
+			results[i].Err = &CSVRowError{Line: rows[i].Line, Err: FieldError{
+				Field:   "reference",
+				Message: "must be given and unique to match the row to its batch shipment"}}
+			continue
+		}
+		byReference[reference] = i
+		shipments = append(shipments, rows[i].Shipment)
+	}
+	if len(shipments) == 0 {
+		return nil
+	}
+	newBatch, err := NewBatch(shipments, false)
+	if err != nil {
+		return err
+	}
+	for _, i := range byReference {
+		results[i].Shipment.BatchId = newBatch.Id
+	}
+	for _, shipment := range newBatch.Shipments {
+		if i, ok := byReference[shipment.Reference]; ok {
+			if shipment.BatchId == "" {
+				shipment.BatchId = newBatch.Id
+			}
+			results[i].Shipment = shipment
+		}
+	}
+	return nil
+}
+
+// WriteCSVResults writes one line per result with the row number, reference,
+// shipment id, tracking code, label URL, price paid and any error.
+func WriteCSVResults(w io.Writer, results []CSVResult) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "reference", "shipment_id", "tracking_code",
+		"label_url", "rate", "error"})
+	for _, result := range results {
+		message, rate := "", ""
+		if result.Err != nil {
+			message = result.Err.Error()
+		}
+		if selected := result.Shipment.SelectedRate; selected.Id != "" {
+			rate = selected.Rate.String()
+		}
+		writer.Write([]string{
+			strconv.Itoa(result.Line),
+			result.Shipment.Reference,
+			result.Shipment.Id,
+			result.Shipment.TrackingCode,
+			result.Shipment.PostageLabel.LabelUrl,
+			rate,
+			message,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
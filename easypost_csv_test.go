@@ -0,0 +1,227 @@
+package easypost
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testCSV = `order,name,street,city,state,zip,country,lbs,l,w,h,item,qty,value,hs,origin
+A1,Jane Doe,1 Main St,Springfield,Illinois,62701,US,2,10,8,4,,,,,
+A2,Jean Roy,5 Rue Peel,Montréal,Quebec,h3b 1a1,Canada,1.5,10,8,4,T-shirt,2,40.00,6109.10,US
+A3,No City,1 Main St,,IL,62701,US,abc,10,8,4,,,,,
+`
+
+var testCSVImporter = CSVImporter{
+	Columns: map[string]string{
+		"reference":                     "order",
+		"to_address.name":               "name",
+		"to_address.street1":            "street",
+		"to_address.city":               "city",
+		"to_address.state":              "state",
+		"to_address.zip":                "zip",
+		"to_address.country":            "country",
+		"parcel.weight":                 "lbs",
+		"parcel.length":                 "l",
+		"parcel.width":                  "w",
+		"parcel.height":                 "h",
+		"customs_item.description":      "item",
+		"customs_item.quantity":         "qty",
+		"customs_item.value":            "value",
+		"customs_item.weight":           "lbs",
+		"customs_item.hs_tariff_number": "hs",
+		"customs_item.origin_country":   "origin",
+	},
+	FromAddress:   Address{Id: "adr_from", Country: "US"},
+	WeightUnit:    Pounds,
+	CustomsSigner: "Steve Brule",
+}
+
+func TestCSVImportRead(t *testing.T) {
+	rows, err := testCSVImporter.Read(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	domestic := rows[0]
+	if domestic.Err != nil {
+		t.Errorf("row 2: %v", domestic.Err)
+	}
+	if domestic.Shipment.Reference != "A1" || domestic.Shipment.ToAddress.State != "IL" {
+		t.Errorf("row 2: got %+v", domestic.Shipment.ToAddress)
+	}
+	if domestic.Shipment.Parcel.Weight.Ounces() != 32 {
+		t.Errorf("row 2: weight %v oz, want 32", domestic.Shipment.Parcel.Weight.Ounces())
+	}
+	if domestic.Shipment.HasCustoms() {
+		t.Error("row 2: unexpected customs")
+	}
+
+	international := rows[1]
+	if international.Err != nil {
+		t.Errorf("row 3: %v", international.Err)
+	}
+	to := international.Shipment.ToAddress
+	if to.Country != "CA" || to.State != "QC" || to.Zip != "H3B 1A1" {
+		t.Errorf("row 3: got %+v", to)
+	}
+	items := international.Shipment.CustomsInfo.CustomsItems
+	if len(items) != 1 || items[0].Value.String() != "40.00" || items[0].Quantity != 2 {
+		t.Errorf("row 3: got customs items %+v", items)
+	}
+
+	invalid := rows[2]
+	rowErr, ok := invalid.Err.(*CSVRowError)
+	if !ok || rowErr.Line != 4 {
+		t.Fatalf("row 4: got error %#v", invalid.Err)
+	}
+	fields := map[string]bool{}
+	for _, e := range rowErr.Err.(ValidationErrors) {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"to_address.city", "parcel.weight"} {
+		if !fields[field] {
+			t.Errorf("row 4: no error for %s in %v", field, rowErr)
+		}
+	}
+	if !strings.HasPrefix(rowErr.Error(), "row 4: ") {
+		t.Errorf("got %q", rowErr.Error())
+	}
+}
+
+func TestCSVImportResults(t *testing.T) {
+	rows, err := testCSVImporter.Read(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []CSVResult{
+		{Line: 2, Shipment: Shipment{Id: "shp_1", Reference: "A1",
+			TrackingCode: "9400", PostageLabel: PostageLabel{LabelUrl: "https://l/1.png"},
+			SelectedRate: Rate{Id: "rate_1", Rate: usd(512)}}},
+		{Line: 4, Shipment: rows[2].Shipment, Err: rows[2].Err},
+	}
+	var out bytes.Buffer
+	if err := WriteCSVResults(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines: %q", len(lines), out.String())
+	}
+	if lines[1] != "2,A1,shp_1,9400,https://l/1.png,5.12," {
+		t.Errorf("got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "4,A3,,,,,") {
+		t.Errorf("got %q", lines[2])
+	}
+}
+
+func TestCSVImportBadRows(t *testing.T) {
+	input := testCSV[:strings.Index(testCSV, "\n")+1] +
+		"A1,Jane Doe,1 Main St,Springfield,IL,62701,US,2,10,8,4\n" +
+		"A2,\"Jean\nRoy\",5 Rue Peel,Montréal,QC,H3B 1A1,CA,1.5,10,8,4,T-shirt,2,40.00,6109.10,US\n" +
+		"A3,Jane Doe,1 Main St,Springfield,IL,62701,US,2,10,8,4,,,,,\n"
+	rows, err := testCSVImporter.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rowErr, ok := rows[0].Err.(*CSVRowError); !ok || rowErr.Line != 2 {
+		t.Errorf("short row: got %v", rows[0].Err)
+	}
+	if rows[1].Line != 3 || rows[1].Err != nil {
+		t.Errorf("multi-line row: line %d, %v", rows[1].Line, rows[1].Err)
+	}
+	if rows[2].Line != 5 || rows[2].Err != nil {
+		t.Errorf("row after a multi-line field: line %d, %v", rows[2].Line, rows[2].Err)
+	}
+
+	importer := testCSVImporter
+	importer.FromAddress = Address{Id: "adr_from"}
+	rows, _ = importer.Read(strings.NewReader(testCSV))
+	rowErr, ok := rows[1].Err.(*CSVRowError)
+	if !ok || rowErr.Err.(ValidationErrors)[0].Field != "from_address.country" {
+		t.Errorf("expected an error for a from address without a country, got %v",
+			rows[1].Err)
+	}
+}
+
+func TestCSVImportBatch(t *testing.T) {
+	var form url.Values
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		form = readForm(r)
+		// The API doesn't promise to list shipments in the order sent.
+		w.Write([]byte(`{"id": "batch_1", "state": "creating", "shipments": [
+			{"id": "shp_2", "reference": "A2"}]}`))
+	})()
+
+	rows, err := testCSVImporter.Read(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows = append(rows, rows[0])
+	rows[3].Line = 5
+	importer := testCSVImporter
+	importer.UseBatch = true
+	results, err := importer.Create(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Get("batch[shipments][0][from_address][id]") != "adr_from" ||
+		form.Get("batch[shipments][1][customs_info][customs_items][0][description]") != "T-shirt" ||
+		form.Get("batch[shipments][2][reference]") != "" {
+		t.Errorf("sent %v", form)
+	}
+	if results[0].Shipment.Id != "" || results[0].Shipment.BatchId != "batch_1" ||
+		results[0].Err != nil {
+		t.Errorf("row 2: got %+v, %v", results[0].Shipment, results[0].Err)
+	}
+	if results[1].Shipment.Id != "shp_2" || results[1].Shipment.BatchId != "batch_1" {
+		t.Errorf("row 3: got %+v", results[1].Shipment)
+	}
+	if results[2].Err != rows[2].Err {
+		t.Errorf("row 4: got %v", results[2].Err)
+	}
+	if rowErr, ok := results[3].Err.(*CSVRowError); !ok || rowErr.Line != 5 {
+		t.Errorf("duplicate reference: got %v", results[3].Err)
+	}
+}
+
+func TestCSVImportCreateFailure(t *testing.T) {
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(readForm(r).Get("shipment[reference]"), "A2") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error": {"code": "SHIPMENT.INVALID_PARAMS", "message": "bad"}}`))
+			return
+		}
+		w.Write([]byte(`{"id": "shp_1", "reference": "A1"}`))
+	})()
+
+	rows, err := testCSVImporter.Read(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := testCSVImporter.Create(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Shipment.Id != "shp_1" || results[1].Err == nil {
+		t.Fatalf("got %+v", results)
+	}
+	var out bytes.Buffer
+	if err = WriteCSVResults(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !strings.HasPrefix(lines[2], "3,A2,,") {
+		t.Errorf("failed row written as %q", lines[2])
+	}
+}
@@ -0,0 +1,224 @@
+package easypost
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ContentsType describes what a customs declaration covers.
+type ContentsType string
+
+const (
+	ContentsDocuments     ContentsType = "documents"
+	ContentsGift          ContentsType = "gift"
+	ContentsMerchandise   ContentsType = "merchandise"
+	ContentsReturnedGoods ContentsType = "returned_goods"
+	ContentsSample        ContentsType = "sample"
+	ContentsOther         ContentsType = "other"
+)
+
+// RestrictionType describes any special treatment the contents need on entry.
+type RestrictionType string
+
+const (
+	RestrictionNone       RestrictionType = "none"
+	RestrictionOther      RestrictionType = "other"
+	RestrictionQuarantine RestrictionType = "quarantine"
+	RestrictionSanitary   RestrictionType = "sanitary_phytosanitary_inspection"
+)
+
+// NonDeliveryOption says what the carrier should do with an undeliverable
+// package.
+type NonDeliveryOption string
+
+const (
+	NonDeliveryReturn  NonDeliveryOption = "return"
+	NonDeliveryAbandon NonDeliveryOption = "abandon"
+)
+
+// hsTariffPattern matches a Harmonized System code of 6, 8 or 10 digits,
+// optionally written with dots between the digit groups, e.g. "6109.10.0012".
+var hsTariffPattern = regexp.MustCompile(`^\d{4}\.?\d{2}(\.?\d{2}){0,2}$`)
+
+// ValidHsTariffNumber reports whether code looks like an HS tariff number.
+func ValidHsTariffNumber(code string) bool {
+	return hsTariffPattern.MatchString(code)
+}
+
+// LineItem is one line of an order as it is needed for customs.
+type LineItem struct {
+	SKU            string
+	Description    string
+	Quantity       int
+	UnitValue      Money
+	UnitWeight     Weight
+	HsTariffNumber string
+	OriginCountry  string // An ISO code or a country name
+}
+
+// CustomsBuilder builds a CustomsInfo from order line items. Fields left
+// empty default to merchandise, no restriction and return to sender.
+// Build signs the declaration, so CustomsSigner must be set.
+type CustomsBuilder struct {
+	ContentsType        ContentsType
+	ContentsExplanation string
+	RestrictionType     RestrictionType
+	RestrictionComments string
+	NonDeliveryOption   NonDeliveryOption
+	CustomsSigner       string
+	EelPfc              string
+	Items               []LineItem
+}
+
+// Add appends line items to the declaration.
+func (b *CustomsBuilder) Add(items ...LineItem) *CustomsBuilder {
+	b.Items = append(b.Items, items...)
+	return b
+}
+
+// Build returns the customs declaration for the line items, one CustomsItem
+// per line with the line's total value and weight. The result has been
+// validated and is ready for NewCustomsInfo or a shipment.
+func (b *CustomsBuilder) Build() (customsInfo CustomsInfo, err error) {
+	customsInfo = CustomsInfo{
+		ContentsType:        b.ContentsType,
+		ContentsExplanation: b.ContentsExplanation,
+		RestrictionType:     b.RestrictionType,
+		RestrictionComments: b.RestrictionComments,
+		NonDeliveryOption:   b.NonDeliveryOption,
+		CustomsCertify:      true,
+		CustomsSigner:       b.CustomsSigner,
+		EelPfc:              b.EelPfc,
+	}
+	if customsInfo.ContentsType == "" {
+		customsInfo.ContentsType = ContentsMerchandise
+	}
+	if customsInfo.RestrictionType == "" {
+		customsInfo.RestrictionType = RestrictionNone
+	}
+	if customsInfo.NonDeliveryOption == "" {
+		customsInfo.NonDeliveryOption = NonDeliveryReturn
+	}
+	var errs ValidationErrors
+	for index, line := range b.Items {
+		origin, ok := NormalizeCountry(line.OriginCountry)
+		if !ok {
+			origin = line.OriginCountry // Left for Validate to report
+		}
+		value, err := line.UnitValue.Mul(int64(line.Quantity))
+		if err != nil {
+			errs = append(errs, FieldError{
+				Field:   "customs_items[" + strconv.Itoa(index) + "].value",
+				Message: "unit value times quantity is out of range"})
+		}
+		customsInfo.CustomsItems = append(customsInfo.CustomsItems, CustomsItem{
+			Description:    strings.TrimSpace(line.Description),
+			Code:           line.SKU,
+			Quantity:       float64(line.Quantity),
+			Value:          value,
+			Currency:       line.UnitValue.Currency,
+			Weight:         line.UnitWeight.Mul(float64(line.Quantity)),
+			HsTariffNumber: line.HsTariffNumber,
+			OriginCountry:  origin,
+		})
+	}
+	if err := customsInfo.Validate(); err != nil {
+		errs = append(err.(ValidationErrors), errs...)
+	}
+	return customsInfo, errs.err()
+}
+
+// TotalValue adds up the value of every item. It fails if the items are
+// valued in different currencies.
+func (c *CustomsInfo) TotalValue() (Money, error) {
+	values := make([]Money, len(c.CustomsItems))
+	for i, item := range c.CustomsItems {
+		values[i] = item.Value
+	}
+	return SumMoney(values...)
+}
+
+// TotalWeight adds up the weight of every item.
+func (c *CustomsInfo) TotalWeight() (total Weight) {
+	for _, item := range c.CustomsItems {
+		total = total.Add(item.Weight)
+	}
+	return total
+}
+
+// Validate checks the declaration locally before it is sent to the API. Items
+// that only reference an existing customs item by Id are not checked.
+func (c *CustomsInfo) Validate() error {
+	var errs ValidationErrors
+	switch c.ContentsType {
+	case "", ContentsDocuments, ContentsGift, ContentsMerchandise,
+		ContentsReturnedGoods, ContentsSample:
+	case ContentsOther:
+		if c.ContentsExplanation == "" {
+			errs = append(errs, FieldError{Field: "contents_explanation",
+				Message: "is required when contents type is other"})
+		}
+	default:
+		errs = append(errs, FieldError{Field: "contents_type",
+			Message: "unknown contents type " + string(c.ContentsType)})
+	}
+	switch c.RestrictionType {
+	case "", RestrictionNone:
+	case RestrictionOther, RestrictionQuarantine, RestrictionSanitary:
+		if c.RestrictionComments == "" {
+			errs = append(errs, FieldError{Field: "restriction_comments",
+				Message: "is required when there is a restriction"})
+		}
+	default:
+		errs = append(errs, FieldError{Field: "restriction_type",
+			Message: "unknown restriction type " + string(c.RestrictionType)})
+	}
+	switch c.NonDeliveryOption {
+	case "", NonDeliveryReturn, NonDeliveryAbandon:
+	default:
+		errs = append(errs, FieldError{Field: "non_delivery_option",
+			Message: "unknown non-delivery option " + string(c.NonDeliveryOption)})
+	}
+	if c.CustomsCertify && c.CustomsSigner == "" {
+		errs = append(errs, FieldError{Field: "customs_signer",
+			Message: "is required to certify the declaration"})
+	}
+	if len(c.CustomsItems) == 0 {
+		errs = append(errs, FieldError{Field: "customs_items",
+			Message: "at least one item is required"})
+	}
+	for index, item := range c.CustomsItems {
+		if item.Id != "" {
+			continue
+		}
+		if err := item.Validate(); err != nil {
+			itemErrs := withPrefix(err, "customs_items["+strconv.Itoa(index)+"].")
+			errs = append(errs, itemErrs.(ValidationErrors)...)
+		}
+	}
+	return errs.err()
+}
+
+// Validate checks the customs item locally before it is sent to the API.
+func (item *CustomsItem) Validate() error {
+	var errs ValidationErrors
+	if strings.TrimSpace(item.Description) == "" {
+		errs = append(errs, FieldError{Field: "description", Message: "is required"})
+	}
+	checkPositive(&errs, "quantity", item.Quantity)
+	checkPositive(&errs, "weight", item.Weight.Ounces())
+	if item.Value.Cmp(Money{}) <= 0 {
+		errs = append(errs, FieldError{Field: "value",
+			Message: "must be greater than zero"})
+	}
+	if item.HsTariffNumber != "" && !ValidHsTariffNumber(item.HsTariffNumber) {
+		errs = append(errs, FieldError{Field: "hs_tariff_number",
+			Message: "must be 6, 8 or 10 digits"})
+	}
+	if _, ok := CountryName(item.OriginCountry); !ok {
+		errs = append(errs, FieldError{Field: "origin_country",
+			Message: "must be an ISO 3166 country code"})
+	}
+	return errs.err()
+}
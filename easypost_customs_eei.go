@@ -0,0 +1,103 @@
+package easypost
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Exemption statements for CustomsInfo.EelPfc on US exports that don't need
+// Electronic Export Information filed in AES.
+const (
+	// EelPfcNoEEI3037a covers shipments where no Schedule B number is worth
+	// more than $2,500.
+	EelPfcNoEEI3037a = "NOEEI 30.37(a)"
+	// EelPfcNoEEI3036 covers shipments to Canada that need no export license,
+	// whatever their value.
+	EelPfcNoEEI3036 = "NOEEI 30.36"
+)
+
+// eeiValueThreshold is the value per Schedule B number above which EEI must
+// be filed.
+var eeiValueThreshold = MoneyFromCents(250000, "USD")
+
+// eeiLicensedDestinations are countries that need an export license, and so
+// EEI, whatever the value of the shipment.
+var eeiLicensedDestinations = map[string]bool{
+	"BY": true, "CU": true, "IR": true, "KP": true, "RU": true, "SY": true,
+}
+
+// itnPattern matches an AES Internal Transaction Number as it is written in
+// EelPfc, e.g. "AES X20150719123456".
+var itnPattern = regexp.MustCompile(`^AES X\d{14}$`)
+
+// AESFilingRequiredError is returned by SelectEelPfc when the shipment needs
+// EEI filed in AES. File it, then put the ITN in EelPfc as "AES X..." and
+// call SelectEelPfc again.
+type AESFilingRequiredError struct {
+	Destination string
+	Reasons     []string
+}
+
+func (e *AESFilingRequiredError) Error() string {
+	return "easypost: AES filing required for export to " + e.Destination +
+		": " + strings.Join(e.Reasons, "; ")
+}
+
+// SelectEelPfc fills in EelPfc for a shipment from the United States to
+// destinationCountry. An ITN already in EelPfc is kept. Otherwise the
+// exemption that applies is filled in, or an *AESFilingRequiredError
+// explains why EEI must be filed. Item values must be in US dollars.
+//
+// Items are grouped by HS tariff number, standing in for the Schedule B
+// number; items without one are grouped by description.
+func (c *CustomsInfo) SelectEelPfc(destinationCountry string) error {
+	if itnPattern.MatchString(c.EelPfc) {
+		return nil
+	}
+	destination, ok := NormalizeCountry(destinationCountry)
+	if !ok {
+		return FieldError{Field: "country",
+			Message: "unknown destination country " + destinationCountry}
+	}
+
+	var reasons []string
+	if eeiLicensedDestinations[destination] {
+		name, _ := CountryName(destination)
+		reasons = append(reasons, "exports to "+name+" require a license")
+	}
+	if destination == "CA" && len(reasons) == 0 {
+		c.EelPfc = EelPfcNoEEI3036
+		return nil
+	}
+
+	totals := map[string]Money{}
+	for _, item := range c.CustomsItems {
+		if item.Value.Currency != "" && !strings.EqualFold(item.Value.Currency, "USD") {
+			return FieldError{Field: "value", Message: "item " +
+				item.Description + " must be valued in USD to determine EEI"}
+		}
+		key := strings.Replace(item.HsTariffNumber, ".", "", -1)
+		if key == "" {
+			key = item.Description
+		}
+		totals[key], _ = totals[key].Add(item.Value)
+	}
+	var over []string
+	for key, total := range totals {
+		if total.Cmp(eeiValueThreshold) > 0 {
+			over = append(over, fmt.Sprintf("%s is valued at $%s", key, total))
+		}
+	}
+	sort.Strings(over)
+	for _, reason := range over {
+		reasons = append(reasons, reason+", over the $2,500 limit")
+	}
+
+	if len(reasons) > 0 {
+		return &AESFilingRequiredError{Destination: destination, Reasons: reasons}
+	}
+	c.EelPfc = EelPfcNoEEI3037a
+	return nil
+}
@@ -0,0 +1,200 @@
+package easypost
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCustomsBuilder(t *testing.T) {
+	builder := &CustomsBuilder{CustomsSigner: "Steven Nelson"}
+	builder.Add(LineItem{
+		SKU:            "TEE-M",
+		Description:    "Cotton t-shirt",
+		Quantity:       3,
+		UnitValue:      usd(1250),
+		UnitWeight:     Ounces(6),
+		HsTariffNumber: "6109.10",
+		OriginCountry:  "United States",
+	}, LineItem{
+		SKU:           "MUG",
+		Description:   "Ceramic mug",
+		Quantity:      2,
+		UnitValue:     usd(899),
+		UnitWeight:    Pounds(1),
+		OriginCountry: "CN",
+	})
+	customsInfo, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customsInfo.ContentsType != ContentsMerchandise ||
+		customsInfo.CustomsItems[0].OriginCountry != "US" ||
+		customsInfo.CustomsItems[0].Value.String() != "37.50" {
+		t.Fatalf("built %+v", customsInfo)
+	}
+	total, err := customsInfo.TotalValue()
+	if err != nil || total.String() != "55.48" || total.Currency != "USD" {
+		t.Fatalf("total value %s %s, %v", total, total.Currency, err)
+	}
+	if customsInfo.TotalWeight().Ounces() != 50 {
+		t.Fatal("total weight is off")
+	}
+}
+
+func TestCustomsBuilderValidation(t *testing.T) {
+	builder := &CustomsBuilder{ContentsType: ContentsOther}
+	builder.Add(LineItem{Description: "Widget", Quantity: 1,
+		UnitValue: usd(100), UnitWeight: Ounces(1), HsTariffNumber: "12345",
+		OriginCountry: "Narnia"})
+	_, err := builder.Build()
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []string{"contents_explanation", "customs_signer",
+		"customs_items[0].hs_tariff_number", "customs_items[0].origin_country"}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Fatalf("unexpected errors %v", errs)
+		}
+	}
+	for _, code := range []string{"610910", "6109.10.00", "6109.10.0012"} {
+		if !ValidHsTariffNumber(code) {
+			t.Fatal(code + " should be valid")
+		}
+	}
+}
+
+func TestSelectEelPfc(t *testing.T) {
+	customsInfo := CustomsInfo{CustomsItems: []CustomsItem{
+		{Description: "Laptop", HsTariffNumber: "8471.30", Value: usd(150000)},
+		{Description: "Laptop", HsTariffNumber: "847130", Value: usd(150000)},
+		{Description: "Bag", HsTariffNumber: "4202.12", Value: usd(5000)},
+	}}
+	err := customsInfo.SelectEelPfc("Germany")
+	aesErr, ok := err.(*AESFilingRequiredError)
+	if !ok || len(aesErr.Reasons) != 1 || customsInfo.EelPfc != "" {
+		t.Fatalf("expected AES filing for two laptops, got %v", err)
+	}
+
+	customsInfo.CustomsItems = customsInfo.CustomsItems[1:]
+	if err := customsInfo.SelectEelPfc("DE"); err != nil ||
+		customsInfo.EelPfc != EelPfcNoEEI3037a {
+		t.Fatalf("got %q, %v", customsInfo.EelPfc, err)
+	}
+	if err := customsInfo.SelectEelPfc("canada"); err != nil ||
+		customsInfo.EelPfc != EelPfcNoEEI3036 {
+		t.Fatalf("got %q, %v", customsInfo.EelPfc, err)
+	}
+	if _, ok := customsInfo.SelectEelPfc("Cuba").(*AESFilingRequiredError); !ok {
+		t.Fatal("exports to Cuba need AES filing")
+	}
+
+	customsInfo.EelPfc = "AES X20150719123456"
+	if err := customsInfo.SelectEelPfc("Cuba"); err != nil {
+		t.Fatal("an ITN should be kept")
+	}
+
+	// Unlicensed exports to Canada are exempt whatever their value.
+	canada := CustomsInfo{CustomsItems: []CustomsItem{
+		{Description: "Laptop", HsTariffNumber: "8471.30", Value: usd(300000)},
+	}}
+	if err := canada.SelectEelPfc("CA"); err != nil ||
+		canada.EelPfc != EelPfcNoEEI3036 {
+		t.Fatalf("got %q, %v for a $3,000 export to Canada", canada.EelPfc, err)
+	}
+}
+
+func TestRequiresCustoms(t *testing.T) {
+	from := Address{Street1: "1111 Main St", City: "Conway", State: "AR", Country: "US"}
+	cases := []struct {
+		to       Address
+		required bool
+	}{
+		{Address{City: "Houston", State: "TX"}, false},
+		{Address{City: "San Juan", State: "PR", Country: "US"}, true},
+		{Address{City: "San Juan", Country: "Puerto Rico"}, true},
+		{Address{City: "APO", State: "AE", Zip: "09001"}, true},
+		{Address{City: "Toronto", State: "ON", Country: "CA"}, true},
+		{Address{Id: "adr_1"}, false},
+	}
+	for _, c := range cases {
+		shipment := Shipment{FromAddress: from, ToAddress: c.to}
+		if required, reason := shipment.RequiresCustoms(); required != c.required {
+			t.Fatalf("%+v: required %v (%s)", c.to, required, reason)
+		}
+	}
+	idOnly := Shipment{FromAddress: from, ToAddress: Address{Id: "adr_1"}}
+	if _, reason := idOnly.RequiresCustoms(); reason != CustomsUndetermined {
+		t.Errorf("an Id-only address gave the reason %q", reason)
+	}
+
+	shipment := Shipment{FromAddress: from, ToAddress: cases[4].to,
+		Parcel: Parcel{Length: Inches(1), Width: Inches(1), Height: Inches(1), Weight: Ounces(1)}}
+	errs, ok := shipment.Preflight().(ValidationErrors)
+	if !ok || len(errs) != 1 || errs[0].Field != "customs_info" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	shipment.CustomsInfo.Id = "cstinfo_1"
+	if err := shipment.Preflight(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewBatchPreflight(t *testing.T) {
+	var form url.Values
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		form = readForm(r)
+		w.Write([]byte(`{"id": "batch_1"}`))
+	})()
+
+	parcel := Parcel{Length: Inches(10), Width: Inches(8), Height: Inches(4),
+		Weight: Ounces(16)}
+	from := Address{Street1: "1111 Main St", City: "Conway", State: "AR", Country: "US"}
+	toronto := Address{City: "Toronto", State: "ON", Country: "CA"}
+	shipments := []Shipment{
+		{FromAddress: from, ToAddress: Address{City: "Houston", State: "TX"}, Parcel: parcel},
+		{FromAddress: from, ToAddress: toronto, Parcel: parcel},
+		{FromAddress: from, ToAddress: toronto, Parcel: Parcel{Weight: Ounces(1)}},
+	}
+	_, err := NewBatch(shipments, false)
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 5 || errs[0].Field != "shipments[1].customs_info" ||
+		errs[1].Field != "shipments[2].parcel.length" ||
+		errs[4].Field != "shipments[2].customs_info" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if form != nil {
+		t.Fatal("a batch that fails preflight must not be sent")
+	}
+
+	shipments = shipments[:2]
+	shipments[1].FromAddress = Address{Id: "adr_warehouse", Country: "US"}
+	shipments[1].CustomsInfo = CustomsInfo{CustomsSigner: "Jo", ContentsType: ContentsMerchandise,
+		CustomsItems: []CustomsItem{{Description: "Shirt", Quantity: 1,
+			Value: usd(1500), Weight: Ounces(6), OriginCountry: "US"}}}
+	if _, err := NewBatch(shipments, false); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"batch[shipments][1][from_address][id]":                            "adr_warehouse",
+		"batch[shipments][1][customs_info][customs_items][0][description]": "Shirt",
+		"batch[shipments][1][customs_info][customs_signer]":                "Jo",
+	} {
+		if got := form.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	form = nil
+	shipments[0].Rates = []Rate{{Carrier: "USPS", Service: "Priority"}}
+	_, err = NewBatch(shipments, true)
+	if errs, ok = err.(ValidationErrors); !ok || len(errs) != 1 ||
+		errs[0].Field != "shipments[1].rates" || form != nil {
+		t.Fatalf("buying a shipment without rates returned %v", err)
+	}
+}
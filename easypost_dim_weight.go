@@ -0,0 +1,168 @@
+package easypost
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DimRules are the sizing rules a carrier uses to price a parcel. Lengths are
+// in inches and divisors in cubic inches per pound, as carriers publish them.
+// Zero limits are not checked.
+type DimRules struct {
+	// Divisor turns a parcel's volume into its dimensional weight in pounds.
+	Divisor float64
+	// MinVolume is the volume at or below which only actual weight is billed.
+	MinVolume float64
+	// OversizeLengthPlusGirth is the length plus girth above which the
+	// carrier charges an oversize surcharge.
+	OversizeLengthPlusGirth Length
+	// MaxLength, MaxLengthPlusGirth and MaxWeight are the largest parcel the
+	// carrier will accept.
+	MaxLength          Length
+	MaxLengthPlusGirth Length
+	MaxWeight          Weight
+	// MachinableLength, MachinableWidth, MachinableHeight and
+	// MachinableWeight are the largest parcel the carrier's sorting
+	// equipment handles without a non-machinable surcharge.
+	MachinableLength Length
+	MachinableWidth  Length
+	MachinableHeight Length
+	MachinableWeight Weight
+}
+
+var dimRulesMu sync.RWMutex
+
+var dimRules = map[string]DimRules{
+	"USPS": {
+		Divisor:                 166,
+		MinVolume:               1728,
+		OversizeLengthPlusGirth: Inches(108),
+		MaxLengthPlusGirth:      Inches(130),
+		MaxWeight:               Pounds(70),
+		MachinableLength:        Inches(22),
+		MachinableWidth:         Inches(18),
+		MachinableHeight:        Inches(15),
+		MachinableWeight:        Pounds(25),
+	},
+	"UPS": {
+		Divisor:                 139,
+		OversizeLengthPlusGirth: Inches(130),
+		MaxLength:               Inches(108),
+		MaxLengthPlusGirth:      Inches(165),
+		MaxWeight:               Pounds(150),
+	},
+	"FEDEX": {
+		Divisor:                 139,
+		OversizeLengthPlusGirth: Inches(130),
+		MaxLength:               Inches(108),
+		MaxLengthPlusGirth:      Inches(165),
+		MaxWeight:               Pounds(150),
+	},
+}
+
+// SetDimRules sets the sizing rules used for carrier, adding a carrier or
+// replacing the built in rules, e.g. with a negotiated divisor.
+func SetDimRules(carrier string, rules DimRules) {
+	dimRulesMu.Lock()
+	defer dimRulesMu.Unlock()
+	dimRules[strings.ToUpper(carrier)] = rules
+}
+
+// LookupDimRules returns the sizing rules for carrier.
+func LookupDimRules(carrier string) (rules DimRules, ok bool) {
+	dimRulesMu.RLock()
+	defer dimRulesMu.RUnlock()
+	rules, ok = dimRules[strings.ToUpper(carrier)]
+	return rules, ok
+}
+
+// ErrNoDimRules is returned when there are no sizing rules for a carrier.
+var ErrNoDimRules = errors.New("easypost: no dimensional weight rules for carrier")
+
+// DimWeightResult is the outcome of sizing a parcel for a carrier. Weights
+// are rounded up to whole pounds the way carriers bill them.
+type DimWeightResult struct {
+	Carrier        string
+	ActualWeight   Weight
+	DimWeight      Weight
+	BillableWeight Weight
+	// Oversize is set when the parcel will incur an oversize surcharge.
+	Oversize bool
+	// NonMachinable is set when the parcel is too large or heavy for the
+	// carrier's sorting equipment.
+	NonMachinable bool
+	// ExceedsLimits is set when the carrier won't accept the parcel at all.
+	ExceedsLimits bool
+}
+
+// CalculateDimWeight works out the dimensional and billable weight of a
+// parcel for a carrier. Predefined packages without dimensions are billed on
+// actual weight.
+func CalculateDimWeight(parc Parcel, carrier string) (result DimWeightResult, err error) {
+	rules, ok := LookupDimRules(carrier)
+	if !ok {
+		return result, ErrNoDimRules
+	}
+	if err = parc.Validate(); err != nil {
+		return result, err
+	}
+	result.Carrier = carrier
+	result.ActualWeight = Pounds(math.Ceil(parc.Weight.Pounds()))
+	result.BillableWeight = result.ActualWeight
+
+	// Carriers measure the longest side as the length and round each side
+	// to the nearest inch.
+	sides := []float64{
+		math.Round(parc.Length.Inches()),
+		math.Round(parc.Width.Inches()),
+		math.Round(parc.Height.Inches()),
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sides)))
+	length, width, height := Inches(sides[0]), Inches(sides[1]), Inches(sides[2])
+	lengthPlusGirth := length.Add(width.Add(height).Mul(2))
+
+	volume := sides[0] * sides[1] * sides[2]
+	if rules.Divisor > 0 && volume > rules.MinVolume {
+		result.DimWeight = Pounds(math.Ceil(volume / rules.Divisor))
+		if result.DimWeight.Ounces() > result.BillableWeight.Ounces() {
+			result.BillableWeight = result.DimWeight
+		}
+	}
+
+	result.Oversize = exceeds(lengthPlusGirth, rules.OversizeLengthPlusGirth)
+	result.ExceedsLimits = exceeds(length, rules.MaxLength) ||
+		exceeds(lengthPlusGirth, rules.MaxLengthPlusGirth) ||
+		exceedsWeight(parc.Weight, rules.MaxWeight)
+	result.NonMachinable = exceeds(length, rules.MachinableLength) ||
+		exceeds(width, rules.MachinableWidth) ||
+		exceeds(height, rules.MachinableHeight) ||
+		exceedsWeight(parc.Weight, rules.MachinableWeight)
+	return result, nil
+}
+
+// AnnotateBillableWeights sets BillableWeight on each of the shipment's rates
+// from its parcel. Rates from carriers without sizing rules are left at zero.
+func AnnotateBillableWeights(shipment *Shipment) error {
+	for key, rate := range shipment.Rates {
+		result, err := CalculateDimWeight(shipment.Parcel, rate.Carrier)
+		if err == ErrNoDimRules {
+			continue
+		} else if err != nil {
+			return err
+		}
+		shipment.Rates[key].BillableWeight = result.BillableWeight
+	}
+	return nil
+}
+
+// exceeds reports whether v is over a limit, treating a zero limit as none.
+func exceeds(v, limit Length) bool {
+	return limit.Inches() > 0 && v.Inches() > limit.Inches()
+}
+
+func exceedsWeight(v, limit Weight) bool {
+	return limit.Ounces() > 0 && v.Ounces() > limit.Ounces()
+}
@@ -0,0 +1,40 @@
+package easypost
+
+import "testing"
+
+func TestCalculateDimWeight(t *testing.T) {
+	// 20x15x12 = 3600 cubic inches, 5 pounds actual.
+	parcel := Parcel{Length: Inches(20), Width: Inches(15), Height: Inches(12), Weight: Pounds(4.2)}
+	usps, err := CalculateDimWeight(parcel, "USPS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usps.DimWeight.Pounds() != 22 || usps.BillableWeight.Pounds() != 22 ||
+		usps.ActualWeight.Pounds() != 5 || usps.NonMachinable {
+		t.Fatalf("unexpected USPS result %+v", usps)
+	}
+	ups, _ := CalculateDimWeight(parcel, "ups")
+	if ups.BillableWeight.Pounds() != 26 {
+		t.Fatalf("unexpected UPS result %+v", ups)
+	}
+
+	long := Parcel{Length: Inches(10), Width: Inches(10), Height: Inches(40), Weight: Pounds(1)}
+	usps, _ = CalculateDimWeight(long, "USPS")
+	if !usps.NonMachinable || usps.Oversize {
+		t.Fatalf("40 inch parcel should be non-machinable only: %+v", usps)
+	}
+
+	if _, err := CalculateDimWeight(parcel, "Acme"); err != ErrNoDimRules {
+		t.Fatal("expected ErrNoDimRules")
+	}
+	SetDimRules("Acme", DimRules{Divisor: 250})
+	t.Cleanup(func() {
+		dimRulesMu.Lock()
+		defer dimRulesMu.Unlock()
+		delete(dimRules, "ACME")
+	})
+	acme, err := CalculateDimWeight(parcel, "ACME")
+	if err != nil || acme.BillableWeight.Pounds() != 15 {
+		t.Fatalf("unexpected custom carrier result %+v, %v", acme, err)
+	}
+}
@@ -0,0 +1,150 @@
+package easypost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DryRun, if set, stops requests from being sent. Each call still runs its
+// local validation and encoding, then the request is recorded and a
+// placeholder object is returned in place of the API's response:
+//
+//	easypost.DryRun = &easypost.DryRunRecorder{Log: os.Stderr}
+//	shipment, err := easypost.NewShipment(&order)
+//	// shipment.Id is "shp_dryrun_1"; DryRun.Requests() holds the request
+//
+// Placeholders carry only an Id and Object, except that shipments and rate
+// lookups return one placeholder rate, carrier and service "DryRun" at
+// "0.00" USD, so that rates can be selected and bought. Code that reads other
+// fields of the response sees them empty.
+var DryRun *DryRunRecorder
+
+// DryRunRequest is a request that DryRun kept from being sent.
+type DryRunRequest struct {
+	Method string
+	Path   string
+	Body   url.Values
+}
+
+// String formats the request on one line, with the body unescaped so that
+// it can be read.
+func (r DryRunRequest) String() string {
+	if len(r.Body) == 0 {
+		return r.Method + " " + r.Path
+	}
+	body, err := url.QueryUnescape(r.Body.Encode())
+	if err != nil {
+		body = r.Body.Encode()
+	}
+	return r.Method + " " + r.Path + " " + body
+}
+
+// DryRunRecorder records the requests made while DryRun is set.
+type DryRunRecorder struct {
+	// Log, if set, has each request written to it as a line.
+	Log io.Writer
+
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// Requests returns the requests recorded so far, oldest first.
+func (d *DryRunRecorder) Requests() []DryRunRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DryRunRequest(nil), d.requests...)
+}
+
+// Reset forgets the recorded requests.
+func (d *DryRunRecorder) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = nil
+}
+
+// dryRunObjects maps the collection a path starts with to the Id prefix and
+// object name of its placeholders.
+var dryRunObjects = map[string][2]string{
+	"addresses":     {"adr", "Address"},
+	"parcels":       {"prcl", "Parcel"},
+	"shipments":     {"shp", "Shipment"},
+	"customs_infos": {"cstinfo", "CustomsInfo"},
+	"customs_items": {"cstitem", "CustomsItem"},
+	"batches":       {"batch", "Batch"},
+	"scan_forms":    {"sf", "ScanForm"},
+	"refunds":       {"rfnd", "Refund"},
+	"trackers":      {"trk", "Tracker"},
+}
+
+// record stores the request and returns the placeholder response for it.
+func (d *DryRunRecorder) record(requestMethod string, path string,
+	data url.Values) []byte {
+	body := url.Values{}
+	for key, values := range data {
+		body[key] = append([]string(nil), values...)
+	}
+	request := DryRunRequest{Method: requestMethod, Path: path, Body: body}
+
+	d.mu.Lock()
+	d.requests = append(d.requests, request)
+	sequence := len(d.requests)
+	if d.Log != nil {
+		fmt.Fprintln(d.Log, "dry run: "+request.String())
+	}
+	d.mu.Unlock()
+	return dryRunResponse(path, sequence)
+}
+
+// dryRunResponse builds the placeholder for a path, shaped like the API's
+// response to it. An Id in the path is kept; otherwise one is made up from
+// sequence.
+func dryRunResponse(path string, sequence int) []byte {
+	segments := strings.Split(strings.Trim(strings.SplitN(path, "?", 2)[0], "/"), "/")
+	last := segments[len(segments)-1]
+	shipmentId := ""
+	if segments[0] == "shipments" && len(segments) > 1 {
+		shipmentId = segments[1]
+	}
+	switch {
+	case segments[0] == "rates" || last == "rates" || last == "rerate":
+		return []byte(`{"rates":[` + dryRunRate(shipmentId, sequence) + `]}`)
+	case last == "smartrate":
+		return []byte(`{"result":[` + dryRunRate(shipmentId, sequence) + `]}`)
+	}
+
+	kind, ok := dryRunObjects[segments[0]]
+	if !ok {
+		return []byte(`{}`)
+	}
+	id := kind[0] + "_dryrun_" + strconv.Itoa(sequence)
+	if len(segments) > 1 && strings.HasPrefix(segments[1], kind[0]+"_") {
+		id = segments[1]
+	}
+	fields := map[string]interface{}{"id": id, "object": kind[1]}
+	if kind[1] == "Shipment" {
+		fields["rates"] = []json.RawMessage{json.RawMessage(dryRunRate(id, sequence))}
+	}
+	placeholder, _ := json.Marshal(fields)
+	if kind[1] == "Address" && (last == "verify" || last == "create_and_verify") {
+		return []byte(`{"address":` + string(placeholder) + `}`)
+	}
+	return placeholder
+}
+
+// dryRunRate builds the placeholder rate for a shipment, or for no shipment
+// if shipmentId is empty.
+func dryRunRate(shipmentId string, sequence int) string {
+	fields := map[string]string{"id": "rate_dryrun_" + strconv.Itoa(sequence),
+		"object": "Rate", "carrier": "DryRun", "service": "DryRun",
+		"rate": "0.00", "currency": "USD"}
+	if shipmentId != "" {
+		fields["shipment_id"] = shipmentId
+	}
+	rate, _ := json.Marshal(fields)
+	return string(rate)
+}
@@ -0,0 +1,103 @@
+package easypost
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	defer func(dryRun *DryRunRecorder, key string) {
+		DryRun, EasyPostApi["Key"] = dryRun, key
+	}(DryRun, EasyPostApi["Key"])
+	var log bytes.Buffer
+	DryRun = &DryRunRecorder{Log: &log}
+	EasyPostApi["Key"] = ""
+
+	shipment, err := NewShipment(&Shipment{
+		ToAddress:   Address{Id: "adr_to"},
+		FromAddress: Address{Id: "adr_from"},
+		Parcel:      Parcel{Length: Inches(10), Width: Inches(8), Height: Inches(4), Weight: Ounces(16)},
+		Reference:   "order-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shipment.Id != "shp_dryrun_1" || shipment.Object != "Shipment" {
+		t.Errorf("got placeholder %+v", shipment)
+	}
+	bought, err := BuyShippingLabel(shipment.Id, "rate_1")
+	if err != nil || bought.Id != shipment.Id {
+		t.Errorf("buy returned %+v, %v", bought, err)
+	}
+	addr, err := CreateAndVerifyAddress(&Address{Street1: "1 Main St"})
+	if err != nil || addr.Id != "adr_dryrun_3" {
+		t.Errorf("create and verify returned %+v, %v", addr, err)
+	}
+	rates, err := RetrieveRates("shp_1")
+	if err != nil || len(rates) != 1 || rates[0].Id != "rate_dryrun_4" ||
+		rates[0].ShipmentId != "shp_1" || rates[0].Carrier != "DryRun" {
+		t.Errorf("rates returned %+v, %v", rates, err)
+	}
+
+	// Local validation still runs and nothing is recorded for failures.
+	if _, err = NewShipment(&Shipment{}); err == nil {
+		t.Error("expected preflight to fail")
+	}
+	if _, err = NewScanForm(&ScanForm{}); err == nil {
+		t.Error("expected a scan form without tracking codes to fail")
+	}
+
+	requests := DryRun.Requests()
+	if len(requests) != 4 {
+		t.Fatalf("recorded %v", requests)
+	}
+	create := requests[0]
+	if create.Method != "POST" || create.Path != "/shipments" ||
+		create.Body.Get("shipment[reference]") != "order-1" {
+		t.Errorf("recorded %v", create)
+	}
+	if requests[1].String() != "POST /shipments/shp_dryrun_1/buy rate[id]=rate_1" {
+		t.Errorf("recorded %q", requests[1].String())
+	}
+	if requests[3].String() != "GET /shipments/shp_1/rates" {
+		t.Errorf("recorded %q", requests[3].String())
+	}
+	if lines := strings.Count(log.String(), "\n"); lines != 4 {
+		t.Errorf("logged %d lines:\n%s", lines, log.String())
+	}
+
+	DryRun.Reset()
+	if len(DryRun.Requests()) != 0 {
+		t.Error("Reset kept requests")
+	}
+}
+
+func TestDryRunSelectAndBuy(t *testing.T) {
+	defer func(dryRun *DryRunRecorder, key string) {
+		DryRun, EasyPostApi["Key"] = dryRun, key
+	}(DryRun, EasyPostApi["Key"])
+	DryRun = &DryRunRecorder{}
+	EasyPostApi["Key"] = ""
+
+	bought, err := CreateAndBuyBest(&Shipment{
+		ToAddress:   Address{Id: "adr_to"},
+		FromAddress: Address{Id: "adr_from"},
+		Parcel:      Parcel{Length: Inches(10), Width: Inches(8), Height: Inches(4), Weight: Ounces(16)},
+	}, RatePolicy{})
+	if err != nil || bought.Id != "shp_dryrun_1" {
+		t.Fatalf("create and buy returned %+v, %v", bought, err)
+	}
+	requests := DryRun.Requests()
+	if len(requests) != 2 ||
+		requests[1].String() != "POST /shipments/shp_dryrun_1/buy rate[id]=rate_dryrun_1" {
+		t.Errorf("recorded %v", requests)
+	}
+
+	results := (&Pipeline{Policy: RatePolicy{}}).RunAll(
+		context.Background(), []Shipment{{Id: "shp_7"}})
+	if results[0].Err != nil || results[0].Shipment.Id != "shp_7" {
+		t.Errorf("pipeline returned %+v", results[0])
+	}
+}
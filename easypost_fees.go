@@ -0,0 +1,37 @@
+package easypost
+
+// FeeType is the kind of charge a Fee is for.
+type FeeType string
+
+const (
+	FeeLabel     FeeType = "LabelFee"
+	FeePostage   FeeType = "PostageFee"
+	FeeInsurance FeeType = "InsuranceFee"
+	FeeTracker   FeeType = "TrackerFee"
+)
+
+// Fee returns the shipment's fee of the given type, if it has one.
+func (s *Shipment) Fee(feeType FeeType) (fee Fee, ok bool) {
+	for _, fee := range s.Fees {
+		if fee.Type == feeType {
+			return fee, true
+		}
+	}
+	return fee, false
+}
+
+// TotalCharged returns the sum of the fees charged for the shipment and not
+// refunded.
+func (s *Shipment) TotalCharged() (Money, error) {
+	var amounts []Money
+	for _, fee := range s.Fees {
+		if fee.Charged && !fee.Refunded {
+			amounts = append(amounts, fee.Amount)
+		}
+	}
+	total, err := SumMoney(amounts...)
+	if total.Currency == "" {
+		total.Currency = "USD"
+	}
+	return total, err
+}
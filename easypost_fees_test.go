@@ -0,0 +1,42 @@
+package easypost
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBuyShippingLabelFees(t *testing.T) {
+	var path string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"id": "shp_1", "tracking_code": "9400",
+			"selected_rate": {"id": "rate_1", "carrier": "USPS", "service": "Priority",
+				"rate": "7.25", "currency": "USD"},
+			"postage_label": {"object": "PostageLabel", "label_url": "https://l/1.png"},
+			"fees": [
+				{"object": "Fee", "type": "LabelFee", "amount": "0.01000", "charged": true, "refunded": false},
+				{"object": "Fee", "type": "PostageFee", "amount": "7.25000", "charged": true, "refunded": false},
+				{"object": "Fee", "type": "InsuranceFee", "amount": "1.00000", "charged": true, "refunded": true}
+			]}`))
+	})()
+
+	shipment, err := BuyShippingLabel("shp_1", "rate_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/shipments/shp_1/buy" || shipment.PostageLabel.Object != "PostageLabel" ||
+		shipment.SelectedRate.ServiceName != "USPS Priority Mail" || shipment.TrackingCode != "9400" {
+		t.Errorf("got %+v", shipment)
+	}
+	postage, ok := shipment.Fee(FeePostage)
+	if !ok || postage.Amount.Cmp(usd(725)) != 0 || postage.Amount.Currency != "USD" {
+		t.Errorf("postage fee %+v", postage)
+	}
+	if _, ok = shipment.Fee(FeeTracker); ok {
+		t.Error("unexpected tracker fee")
+	}
+	total, err := shipment.TotalCharged()
+	if err != nil || total.String() != "7.26" {
+		t.Errorf("TotalCharged = %v, %v; want 7.26", total, err)
+	}
+}
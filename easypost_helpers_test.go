@@ -0,0 +1,27 @@
+package easypost
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// useTestServer points the API at a local server running handler, and
+// returns a function that restores the configuration and stops the server.
+func useTestServer(handler http.HandlerFunc) func() {
+	server := httptest.NewServer(handler)
+	key, base := EasyPostApi["Key"], EasyPostApi["BaseUrl"]
+	EasyPostApi["Key"], EasyPostApi["BaseUrl"] = "test", server.URL
+	return func() {
+		EasyPostApi["Key"], EasyPostApi["BaseUrl"] = key, base
+		server.Close()
+	}
+}
+
+// readForm decodes the form sent in a request's body.
+func readForm(r *http.Request) url.Values {
+	body, _ := ioutil.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+	return form
+}
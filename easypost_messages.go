@@ -0,0 +1,58 @@
+package easypost
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnratedCarrier is a carrier that returned no rates for a shipment, with
+// the messages it gave as reasons. Reasons is empty if it said nothing.
+type UnratedCarrier struct {
+	Carrier string
+	Reasons []ShipmentMessage
+}
+
+func (u UnratedCarrier) Error() string {
+	if len(u.Reasons) == 0 {
+		return u.Carrier + ": no rates returned"
+	}
+	messages := make([]string, len(u.Reasons))
+	for i, reason := range u.Reasons {
+		messages[i] = reason.Message
+	}
+	return u.Carrier + ": " + strings.Join(messages, "; ")
+}
+
+// UnratedCarriers reports which of the given carriers returned no rates for
+// the shipment, and why. With no carriers given, it reports every carrier
+// that sent a message and has no rates. Carrier names are compared without
+// regard to case.
+func (s *Shipment) UnratedCarriers(carriers ...string) []UnratedCarrier {
+	rated := map[string]bool{}
+	for _, rate := range s.Rates {
+		rated[strings.ToUpper(rate.Carrier)] = true
+	}
+	reasons := map[string][]ShipmentMessage{}
+	for _, message := range s.Messages {
+		key := strings.ToUpper(message.Carrier)
+		reasons[key] = append(reasons[key], message)
+	}
+	if len(carriers) == 0 {
+		for _, message := range s.Messages {
+			if indexFold(carriers, message.Carrier, -1) < 0 {
+				carriers = append(carriers, message.Carrier)
+			}
+		}
+		sort.Strings(carriers)
+	}
+
+	var unrated []UnratedCarrier
+	for _, carrier := range carriers {
+		key := strings.ToUpper(carrier)
+		if !rated[key] {
+			unrated = append(unrated, UnratedCarrier{Carrier: carrier,
+				Reasons: reasons[key]})
+		}
+	}
+	return unrated
+}
@@ -0,0 +1,42 @@
+package easypost
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestShipmentMessages(t *testing.T) {
+	var shipment Shipment
+	err := json.Unmarshal([]byte(`{
+		"rates": [{"id": "rate_1", "carrier": "USPS", "service": "Priority", "rate": "7.25"}],
+		"messages": [
+			{"carrier": "UPS", "carrier_account_id": "ca_ups", "type": "rate_error",
+			 "message": "Invalid credentials"},
+			{"carrier": "FedEx", "type": "rate_error",
+			 "message": {"code": "UNSUPPORTED", "text": "Destination not served"}},
+			{"carrier": "USPS", "type": "rate_message", "message": "No Parcel Select"}
+		]}`), &shipment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shipment.Messages) != 3 || shipment.Messages[0].CarrierAccountId != "ca_ups" {
+		t.Fatalf("got messages %+v", shipment.Messages)
+	}
+	if got := shipment.Messages[1].Message; got != `{"code": "UNSUPPORTED", "text": "Destination not served"}` {
+		t.Errorf("object message decoded as %q", got)
+	}
+
+	unrated := shipment.UnratedCarriers()
+	if len(unrated) != 2 || unrated[0].Carrier != "FedEx" || unrated[1].Carrier != "UPS" {
+		t.Fatalf("got %+v", unrated)
+	}
+	if got := unrated[1].Error(); got != "UPS: Invalid credentials" {
+		t.Errorf("got %q", got)
+	}
+
+	unrated = shipment.UnratedCarriers("usps", "DHLExpress")
+	if len(unrated) != 1 || unrated[0].Carrier != "DHLExpress" ||
+		unrated[0].Error() != "DHLExpress: no rates returned" {
+		t.Errorf("got %+v", unrated)
+	}
+}
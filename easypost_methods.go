@@ -0,0 +1,120 @@
+package easypost
+
+import "errors"
+
+// Label file formats for ConvertLabel and Shipment.Label.
+const (
+	LabelPNG  = "PNG"
+	LabelPDF  = "PDF"
+	LabelZPL  = "ZPL"
+	LabelEPL2 = "EPL2"
+)
+
+// errNoId is returned by the methods below when the receiver hasn't been
+// created through the API yet.
+var errNoId = errors.New("object has no Id; create it first")
+
+// Buy buys the rate, which must be one of the shipment's, and updates the
+// shipment with the purchase. A rate of another shipment is refused before
+// anything is sent.
+func (s *Shipment) Buy(rate Rate) error {
+	if s.Id == "" {
+		return errNoId
+	}
+	if rate.ShipmentId != "" && rate.ShipmentId != s.Id {
+		return errors.New("rate " + rate.Id + " belongs to shipment " +
+			rate.ShipmentId + ", not " + s.Id)
+	}
+	bought, err := BuyShippingLabel(s.Id, rate.Id)
+	if err == nil {
+		*s = bought
+	}
+	return err
+}
+
+// Refund asks for the shipment's label to be refunded. The outcome is in
+// RefundStatus once the shipment is updated.
+func (s *Shipment) Refund() error {
+	if s.Id == "" {
+		return errNoId
+	}
+	response, err := refundShipment(s.Id)
+	var refunded Shipment
+	if err == nil {
+		err = handleJson(response, &refunded)
+	}
+	if err == nil {
+		*s = refunded
+	}
+	return err
+}
+
+// Track updates the shipment's Tracker, creating one from the tracking code
+// if the shipment doesn't have one yet.
+func (s *Shipment) Track() error {
+	var tracker Tracker
+	var err error
+	switch {
+	case s.Tracker.Id != "":
+		tracker, err = RetrieveTracker(s.Tracker.Id)
+	case s.TrackingCode != "":
+		tracker, err = NewTracker(s.TrackingCode, s.SelectedRate.Carrier)
+	default:
+		return errors.New("shipment has no tracking code; buy a label first")
+	}
+	if err == nil {
+		s.Tracker = tracker
+	}
+	return err
+}
+
+// Insure buys insurance for amount, in US dollars, and updates the shipment.
+func (s *Shipment) Insure(amount Money) error {
+	if s.Id == "" {
+		return errNoId
+	}
+	insured, err := InsureShipment(s.Id, amount)
+	if err == nil {
+		*s = insured
+	}
+	return err
+}
+
+// Label converts the shipment's label to format, one of the Label
+// constants, and updates the shipment with the new label URL.
+func (s *Shipment) Label(format string) error {
+	if s.Id == "" {
+		return errNoId
+	}
+	converted, err := ConvertLabel(s.Id, format)
+	if err == nil {
+		*s = converted
+	}
+	return err
+}
+
+// Refresh updates the batch with its current state, e.g. to follow its
+// progress after Buy.
+func (b *Batch) Refresh() error {
+	if b.Id == "" {
+		return errNoId
+	}
+	refreshed, err := RetrieveBatch(b.Id)
+	if err == nil {
+		*b = refreshed
+	}
+	return err
+}
+
+// Buy buys labels for every shipment in the batch and updates it. Buying
+// is asynchronous; Refresh the batch until its Status shows the purchases.
+func (b *Batch) Buy() error {
+	if b.Id == "" {
+		return errNoId
+	}
+	bought, err := BuyBatch(b.Id)
+	if err == nil {
+		*b = bought
+	}
+	return err
+}
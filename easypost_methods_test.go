@@ -0,0 +1,151 @@
+package easypost
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestShipmentMethods(t *testing.T) {
+	var requests []string
+	var form map[string][]string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		form = readForm(r)
+		switch r.URL.Path {
+		case "/shipments/shp_1/buy":
+			w.Write([]byte(`{"id": "shp_1", "tracking_code": "9400",
+				"selected_rate": {"id": "rate_1", "carrier": "USPS"}}`))
+		case "/shipments/shp_1/insure":
+			w.Write([]byte(`{"id": "shp_1", "tracking_code": "9400", "insurance": "100.00"}`))
+		case "/shipments/shp_1/label":
+			w.Write([]byte(`{"id": "shp_1", "postage_label": {"label_pdf_url": "https://l/1.pdf"}}`))
+		case "/shipments/shp_1/refund":
+			w.Write([]byte(`{"id": "shp_1", "refund_status": "submitted"}`))
+		case "/trackers":
+			w.Write([]byte(`{"id": "trk_1", "tracking_code": "9400", "status": "pre_transit"}`))
+		case "/trackers/trk_1":
+			w.Write([]byte(`{"id": "trk_1", "tracking_code": "9400", "status": "delivered"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "NOT_FOUND", "message": "not found"}}`))
+		}
+	})()
+
+	shipment := Shipment{Id: "shp_1"}
+	if err := shipment.Buy(Rate{Id: "rate_9", ShipmentId: "shp_9"}); err == nil ||
+		requests != nil {
+		t.Fatalf("buying another shipment's rate returned %v after %q", err, requests)
+	}
+	if err := shipment.Buy(Rate{Id: "rate_1", ShipmentId: "shp_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if shipment.TrackingCode != "9400" || form["rate[id]"][0] != "rate_1" {
+		t.Errorf("after Buy got %+v", shipment)
+	}
+	if err := shipment.Track(); err != nil || shipment.Tracker.Status != "pre_transit" {
+		t.Errorf("after Track got %+v, %v", shipment.Tracker, err)
+	}
+	if form["tracker[carrier]"][0] != "USPS" {
+		t.Errorf("tracker created with %v", form)
+	}
+	if err := shipment.Track(); err != nil || shipment.Tracker.Status != "delivered" {
+		t.Errorf("after second Track got %+v, %v", shipment.Tracker, err)
+	}
+	if err := shipment.Insure(MoneyFromCents(10000, "USD")); err != nil ||
+		shipment.Insurance.String() != "100.00" || form["amount"][0] != "100.00" {
+		t.Errorf("after Insure got %v, %v", shipment.Insurance, err)
+	}
+	if err := shipment.Insure(MoneyFromCents(10000, "usd")); err != nil {
+		t.Errorf("insurance in lower case usd refused: %v", err)
+	}
+	if err := shipment.Insure(MoneyFromCents(10000, "EUR")); err == nil {
+		t.Error("expected insurance in EUR to be refused")
+	}
+	if err := shipment.Label(LabelPDF); err != nil ||
+		shipment.PostageLabel.LabelPDFUrl != "https://l/1.pdf" {
+		t.Errorf("after Label got %+v, %v", shipment.PostageLabel, err)
+	}
+	if err := shipment.Refund(); err != nil || shipment.RefundStatus != "submitted" {
+		t.Errorf("after Refund got %q, %v", shipment.RefundStatus, err)
+	}
+
+	want := []string{
+		"POST /shipments/shp_1/buy",
+		"POST /trackers",
+		"GET /trackers/trk_1",
+		"POST /shipments/shp_1/insure",
+		"POST /shipments/shp_1/insure",
+		"GET /shipments/shp_1/label?file_format=PDF",
+		"POST /shipments/shp_1/refund",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("sent %q, want %q", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d was %q, want %q", i, requests[i], want[i])
+		}
+	}
+
+	if err := (&Shipment{}).Buy(Rate{Id: "rate_1"}); err != errNoId {
+		t.Errorf("Buy without an Id returned %v", err)
+	}
+}
+
+func TestBatchMethods(t *testing.T) {
+	var requests []string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/batches/batch_1/buy" {
+			w.Write([]byte(`{"id": "batch_1", "state": "purchasing"}`))
+			return
+		}
+		w.Write([]byte(`{"id": "batch_1", "status": {"postage_purchased": 2}}`))
+	})()
+
+	batch := Batch{Id: "batch_1"}
+	if err := batch.Buy(); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Refresh(); err != nil || batch.Status.PostagePurchased != 2 {
+		t.Errorf("after Refresh got %+v, %v", batch, err)
+	}
+	if len(requests) != 2 || requests[0] != "POST /batches/batch_1/buy" ||
+		requests[1] != "GET /batches/batch_1" {
+		t.Errorf("sent %q", requests)
+	}
+}
+
+func TestBatchShipmentPaths(t *testing.T) {
+	var requests []string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"id": "batch_1"}`))
+	})()
+
+	if _, err := AddShipmentsToBatch("batch_1", []string{"shp_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RemoveShipmentsFromBatch("batch_1", []string{"shp_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0] != "POST /batches/batch_1/add_shipments" ||
+		requests[1] != "POST /batches/batch_1/remove_shipments" {
+		t.Errorf("sent %q", requests)
+	}
+}
+
+func TestNewRefundIsPost(t *testing.T) {
+	var request string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		request = r.Method + " " + r.URL.Path
+		w.Write([]byte(`{"id": "shp_1", "refund_status": "submitted"}`))
+	})()
+
+	if _, err := NewRefund("shp_1"); err != nil {
+		t.Fatal(err)
+	}
+	if request != "POST /shipments/shp_1/refund" {
+		t.Errorf("sent %q", request)
+	}
+}
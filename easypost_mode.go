@@ -0,0 +1,78 @@
+package easypost
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GuardPurchases, when set, makes every request that spends money fail with
+// a *ProductionPurchaseError if the API key is a production key, unless
+// AllowProductionPurchases is also set. Purchases are label, batch and
+// pickup purchases and insurance. Test keys are never refused.
+//
+// Set GuardPurchases where keys are configured for development and tests,
+// and AllowProductionPurchases only in the program that ships real
+// packages.
+var (
+	GuardPurchases           bool
+	AllowProductionPurchases bool
+)
+
+// purchasePaths match the endpoints that charge the account when POSTed to.
+var purchasePaths = []*regexp.Regexp{
+	regexp.MustCompile(`^/shipments/[^/]+/(buy|insure)$`),
+	regexp.MustCompile(`^/batches/create_and_buy$`),
+	regexp.MustCompile(`^/batches/[^/]+/buy$`),
+	regexp.MustCompile(`^/pickups/[^/]+/buy$`),
+	regexp.MustCompile(`^/insurances$`),
+}
+
+// ProductionPurchaseError is returned for a purchase refused by
+// GuardPurchases.
+type ProductionPurchaseError struct {
+	Path string
+}
+
+func (e *ProductionPurchaseError) Error() string {
+	return "easypost: refusing purchase " + e.Path + " with a production API key; " +
+		"set AllowProductionPurchases to allow it"
+}
+
+// KeyMode returns the mode of an API key from its prefix: test keys start
+// with "EZTK" and production keys with "EZAK". Keys that match neither are
+// treated as production keys, so that the guard errs on the side of
+// refusing.
+func KeyMode(key string) Mode {
+	if strings.HasPrefix(key, "EZTK") {
+		return ModeTest
+	}
+	return ModeProduction
+}
+
+// CurrentMode returns the mode of the configured API key.
+func CurrentMode() Mode {
+	return KeyMode(EasyPostApi["Key"])
+}
+
+// isPurchase reports whether a request spends money.
+func isPurchase(requestMethod string, path string) bool {
+	if requestMethod != "POST" {
+		return false
+	}
+	path = strings.SplitN(path, "?", 2)[0]
+	for _, pattern := range purchasePaths {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// guardPurchase returns the error for a request refused by GuardPurchases.
+func guardPurchase(requestMethod string, path string) error {
+	if GuardPurchases && !AllowProductionPurchases &&
+		CurrentMode() == ModeProduction && isPurchase(requestMethod, path) {
+		return &ProductionPurchaseError{Path: path}
+	}
+	return nil
+}
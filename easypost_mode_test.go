@@ -0,0 +1,71 @@
+package easypost
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestKeyMode(t *testing.T) {
+	for key, want := range map[string]Mode{
+		"EZTK0123456789": ModeTest,
+		"EZAK0123456789": ModeProduction,
+		"unknown":        ModeProduction,
+	} {
+		if got := KeyMode(key); got != want {
+			t.Errorf("KeyMode(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGuardPurchases(t *testing.T) {
+	requests := 0
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"id": "shp_1"}`))
+	})()
+	defer func(guard, allow bool) {
+		GuardPurchases, AllowProductionPurchases = guard, allow
+	}(GuardPurchases, AllowProductionPurchases)
+	GuardPurchases, AllowProductionPurchases = true, false
+
+	EasyPostApi["Key"] = "EZAK_production"
+	purchases := map[string]func() error{
+		"label": func() error { _, err := BuyShippingLabel("shp_1", "rate_1"); return err },
+		"insurance": func() error {
+			_, err := InsureShipment("shp_1", usd(10000))
+			return err
+		},
+		"batch": func() error { _, err := BuyBatch("batch_1"); return err },
+		"create and buy": func() error {
+			_, err := NewBatch([]Shipment{{
+				Parcel: Parcel{Length: Inches(10), Width: Inches(8), Height: Inches(4), Weight: Ounces(16)},
+				Rates:  []Rate{{Carrier: "USPS", Service: "Priority"}},
+			}}, true)
+			return err
+		},
+	}
+	for name, purchase := range purchases {
+		if _, ok := purchase().(*ProductionPurchaseError); !ok {
+			t.Errorf("%s purchase was not refused", name)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("%d refused purchases reached the API", requests)
+	}
+	if _, err := RetrieveShipment("shp_1"); err != nil {
+		t.Errorf("guard refused a lookup: %v", err)
+	}
+
+	AllowProductionPurchases = true
+	if _, err := BuyShippingLabel("shp_1", "rate_1"); err != nil {
+		t.Errorf("allowed purchase failed: %v", err)
+	}
+	AllowProductionPurchases = false
+	EasyPostApi["Key"] = "EZTK_test"
+	if _, err := BuyShippingLabel("shp_1", "rate_1"); err != nil {
+		t.Errorf("test mode purchase failed: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
@@ -1,201 +1,688 @@
 package easypost
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type EasyPostMessage struct {
-	Message string
+	Message string `json:"message,omitempty"`
 }
 
 type EasyPostResponse struct {
-	Error string
+	Error string `json:"error,omitempty"`
 }
 
 type VerifiedAddress struct {
-	Address Address
-	Message EasyPostMessage
+	Address Address         `json:"address"`
+	Message EasyPostMessage `json:"message"`
+}
+
+// APIError is an error reported by the EasyPost API.
+type APIError struct {
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if e.Code != "" {
+		message = e.Code + ": " + message
+	}
+	for _, fieldError := range e.Errors {
+		message += "; " + fieldError.Error()
+	}
+	return message
 }
 
 type Address struct {
-	Id        string
-	Object    string
-	Error     string
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Name      string
-	Company   string
-	Street1   string
-	Street2   string
-	City      string
-	State     string
-	Zip       string
-	Country   string
-	Email     string
-	Phone     string
+	Id              string          `json:"id,omitempty"`
+	Object          string          `json:"object,omitempty"`
+	Mode            Mode            `json:"mode,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Name            string          `json:"name,omitempty"`
+	Company         string          `json:"company,omitempty"`
+	Street1         string          `json:"street1,omitempty"`
+	Street2         string          `json:"street2,omitempty"`
+	City            string          `json:"city,omitempty"`
+	State           string          `json:"state,omitempty"`
+	Zip             string          `json:"zip,omitempty"`
+	Country         string          `json:"country,omitempty"`
+	Email           string          `json:"email,omitempty"`
+	Phone           string          `json:"phone,omitempty"`
+	Residential     bool            `json:"residential,omitempty"`
+	CarrierFacility string          `json:"carrier_facility,omitempty"`
+	FederalTaxId    string          `json:"federal_tax_id,omitempty"`
+	StateTaxId      string          `json:"state_tax_id,omitempty"`
+	Verifications   Verifications   `json:"verifications"`
+	Raw             json.RawMessage `json:"-"`
+}
+
+func (a *Address) UnmarshalJSON(data []byte) error {
+	type address Address // Has no UnmarshalJSON method, so no recursion
+	if err := json.Unmarshal(data, (*address)(a)); err != nil {
+		return err
+	}
+	a.Raw = copyRaw(data)
+	return nil
 }
 
+func (a Address) MarshalJSON() ([]byte, error) {
+	type address Address
+	return marshalModel(address(a), a.Raw, new(Address))
+}
+
+// VerifyOption requests a verification when creating an address with
+// NewAddress. A strict verification that fails stops the address from being
+// created; otherwise the address is created and the outcome is reported in
+// its Verifications.
+type VerifyOption struct {
+	Check  string
+	Strict bool
+}
+
+var (
+	VerifyDelivery       = VerifyOption{Check: "delivery"}
+	VerifyZip4           = VerifyOption{Check: "zip4"}
+	VerifyDeliveryStrict = VerifyOption{Check: "delivery", Strict: true}
+	VerifyZip4Strict     = VerifyOption{Check: "zip4", Strict: true}
+)
+
+// Verifications holds the results of the verifications requested when the
+// address was created.
+type Verifications struct {
+	Delivery Verification `json:"delivery"`
+	Zip4     Verification `json:"zip4"`
+}
+
+type Verification struct {
+	Success bool                `json:"success,omitempty"`
+	Errors  []FieldError        `json:"errors,omitempty"`
+	Details VerificationDetails `json:"details"`
+}
+
+type VerificationDetails struct {
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	TimeZone  string  `json:"time_zone,omitempty"`
+}
+
+// Rate is a price quoted for a shipment. Currency, ListCurrency and
+// RetailCurrency are filled in from the JSON along with the amounts they
+// apply to, and written back from the amounts, so set the currency of Rate,
+// ListRate and RetailRate rather than these fields.
 type Rate struct {
-	Id          string
-	Object      string
-	Error       string
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Service     string
-	ServiceName string
-	Rate        string
-	RateFloat   float64
-	Carrier     string
-	ShipmentId  string `json:"shipment_id"`
+	Id                     string          `json:"id,omitempty"`
+	Object                 string          `json:"object,omitempty"`
+	Mode                   Mode            `json:"mode,omitempty"`
+	Error                  string          `json:"error,omitempty"`
+	CreatedAt              time.Time       `json:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	Service                string          `json:"service,omitempty"`
+	ServiceName            string          `json:"-"`
+	Rate                   Money           `json:"rate"`
+	Currency               string          `json:"currency,omitempty"`
+	ListRate               Money           `json:"list_rate"`
+	ListCurrency           string          `json:"list_currency,omitempty"`
+	RetailRate             Money           `json:"retail_rate"`
+	RetailCurrency         string          `json:"retail_currency,omitempty"`
+	Carrier                string          `json:"carrier,omitempty"`
+	CarrierAccountId       string          `json:"carrier_account_id,omitempty"`
+	ShipmentId             string          `json:"shipment_id,omitempty"`
+	DeliveryDays           int             `json:"delivery_days,omitempty"`
+	DeliveryDate           time.Time       `json:"delivery_date"`
+	DeliveryDateGuaranteed bool            `json:"delivery_date_guaranteed,omitempty"`
+	EstDeliveryDays        int             `json:"est_delivery_days,omitempty"`
+	BillableWeight         Weight          `json:"-"` // See AnnotateBillableWeights
+	Raw                    json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a rate, copies each currency into the amount it
+// applies to, fills in ServiceName from the service catalog and keeps the
+// JSON in Raw.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	type rate Rate // Has no UnmarshalJSON method, so no recursion
+	if err := json.Unmarshal(data, (*rate)(r)); err != nil {
+		return err
+	}
+	r.ServiceName = ServiceName(r.Carrier, r.Service)
+	r.Rate.Currency = r.Currency
+	r.ListRate.Currency = r.ListCurrency
+	r.RetailRate.Currency = r.RetailCurrency
+	r.Raw = copyRaw(data)
+	return nil
+}
+
+// MarshalJSON writes each currency from the amount it applies to, so that
+// the amounts are the only place currencies need to be set.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	type rate Rate
+	r.Currency = r.Rate.Currency
+	r.ListCurrency = r.ListRate.Currency
+	r.RetailCurrency = r.RetailRate.Currency
+	return marshalModel(rate(r), r.Raw, new(Rate))
+}
+
+// SmartRate is a Rate along with the carrier's historical time in transit
+// for the service between the shipment's origin and destination.
+type SmartRate struct {
+	Rate
+	TimeInTransit TimeInTransit `json:"time_in_transit"`
+}
+
+// UnmarshalJSON is needed because the method promoted from the embedded Rate
+// would otherwise skip TimeInTransit.
+func (r *SmartRate) UnmarshalJSON(data []byte) error {
+	if err := r.Rate.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var transit struct {
+		TimeInTransit TimeInTransit `json:"time_in_transit"`
+	}
+	err := json.Unmarshal(data, &transit)
+	r.TimeInTransit = transit.TimeInTransit
+	return err
+}
+
+// MarshalJSON is needed for the same reason as UnmarshalJSON.
+func (r SmartRate) MarshalJSON() ([]byte, error) {
+	data, err := r.Rate.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var original SmartRate
+	if r.Raw != nil {
+		if err = original.UnmarshalJSON(r.Raw); err != nil {
+			return nil, err
+		}
+		if original.TimeInTransit == r.TimeInTransit {
+			return data, nil
+		}
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "time_in_transit")
+	if r.TimeInTransit != (TimeInTransit{}) {
+		if fields["time_in_transit"], err = json.Marshal(r.TimeInTransit); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// TimeInTransit holds the number of business days within which the given
+// percentage of past packages for a service were delivered.
+type TimeInTransit struct {
+	Percentile50 int `json:"percentile_50,omitempty"`
+	Percentile75 int `json:"percentile_75,omitempty"`
+	Percentile85 int `json:"percentile_85,omitempty"`
+	Percentile90 int `json:"percentile_90,omitempty"`
+	Percentile95 int `json:"percentile_95,omitempty"`
+	Percentile97 int `json:"percentile_97,omitempty"`
+	Percentile99 int `json:"percentile_99,omitempty"`
 }
 
 type ScanForm struct {
-	Id            string
-	Object        string
-	Error         string
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Address       Address
-	TrackingCodes []string `json:"tracking_codes"`
-	FormUrl       string   `json:"form_url"`
-	FormFileType  string   `json:"form_file_type"`
+	Id                 string          `json:"id,omitempty"`
+	Object             string          `json:"object,omitempty"`
+	Mode               Mode            `json:"mode,omitempty"`
+	Error              string          `json:"error,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	Status             ScanFormStatus  `json:"status,omitempty"`
+	Message            string          `json:"message,omitempty"`
+	Address            Address         `json:"address"`
+	TrackingCodes      []string        `json:"tracking_codes,omitempty"`
+	FormUrl            string          `json:"form_url,omitempty"`
+	FormFileType       string          `json:"form_file_type,omitempty"`
+	BatchId            string          `json:"batch_id,omitempty"`
+	ConfirmationNumber string          `json:"confirmation_number,omitempty"`
+	Raw                json.RawMessage `json:"-"`
+}
+
+func (f *ScanForm) UnmarshalJSON(data []byte) error {
+	type scanForm ScanForm
+	if err := json.Unmarshal(data, (*scanForm)(f)); err != nil {
+		return err
+	}
+	f.Raw = copyRaw(data)
+	return nil
+}
+
+func (f ScanForm) MarshalJSON() ([]byte, error) {
+	type scanForm ScanForm
+	return marshalModel(scanForm(f), f.Raw, new(ScanForm))
 }
 
 type CustomsInfo struct {
-	Id                  string
-	Object              string
-	Error               string
-	CreatedAt           time.Time     `json:"created_at"`
-	UpdatedAt           time.Time     `json:"updated_at"`
-	ContentsExplanation string        `json:""`
-	ContentsType        string        `json:"contents_type"`
-	CustomsCertify      bool          `json:"customs_certify"`
-	CustomsSigner       string        `json:"customs_signer"`
-	EelPfc              string        `json:"eel_pfc"`
-	NonDeliveryOption   string        `json:"non_delivery_option"`
-	RestrictionComments string        `json:"restriction_comments"`
-	RestrictionType     string        `json:"restriction_type"`
-	CustomsItems        []CustomsItem `json:"customs_items"`
+	Id                  string            `json:"id,omitempty"`
+	Object              string            `json:"object,omitempty"`
+	Mode                Mode              `json:"mode,omitempty"`
+	Error               string            `json:"error,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	ContentsExplanation string            `json:"contents_explanation,omitempty"`
+	ContentsType        ContentsType      `json:"contents_type,omitempty"`
+	CustomsCertify      bool              `json:"customs_certify,omitempty"`
+	CustomsSigner       string            `json:"customs_signer,omitempty"`
+	EelPfc              string            `json:"eel_pfc,omitempty"`
+	NonDeliveryOption   NonDeliveryOption `json:"non_delivery_option,omitempty"`
+	RestrictionComments string            `json:"restriction_comments,omitempty"`
+	RestrictionType     RestrictionType   `json:"restriction_type,omitempty"`
+	CustomsItems        []CustomsItem     `json:"customs_items,omitempty"`
+	Raw                 json.RawMessage   `json:"-"`
+}
+
+func (c *CustomsInfo) UnmarshalJSON(data []byte) error {
+	type customsInfo CustomsInfo
+	if err := json.Unmarshal(data, (*customsInfo)(c)); err != nil {
+		return err
+	}
+	c.Raw = copyRaw(data)
+	return nil
+}
+
+func (c CustomsInfo) MarshalJSON() ([]byte, error) {
+	type customsInfo CustomsInfo
+	return marshalModel(customsInfo(c), c.Raw, new(CustomsInfo))
 }
 
 type CustomsItem struct {
-	Id             string
-	Object         string
-	Error          string
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Description    string
-	HsTariffNumber string `json:"hs_tariff_number"`
-	OriginCountry  string `json:"origin_country"`
-	Quantity       float64
-	Value          string
-	Weight         float64
+	Id             string          `json:"id,omitempty"`
+	Object         string          `json:"object,omitempty"`
+	Mode           Mode            `json:"mode,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Description    string          `json:"description,omitempty"`
+	Code           string          `json:"code,omitempty"` // The seller's SKU or product code
+	HsTariffNumber string          `json:"hs_tariff_number,omitempty"`
+	OriginCountry  string          `json:"origin_country,omitempty"`
+	Quantity       float64         `json:"quantity,omitempty"`
+	Value          Money           `json:"value"`
+	Currency       string          `json:"currency,omitempty"` // Written from Value when encoded
+	Weight         Weight          `json:"weight,omitempty"`
+	Raw            json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a customs item and copies its currency into Value.
+func (c *CustomsItem) UnmarshalJSON(data []byte) error {
+	type customsItem CustomsItem
+	if err := json.Unmarshal(data, (*customsItem)(c)); err != nil {
+		return err
+	}
+	c.Value.Currency = c.Currency
+	c.Raw = copyRaw(data)
+	return nil
+}
+
+// MarshalJSON writes the currency from Value.
+func (c CustomsItem) MarshalJSON() ([]byte, error) {
+	type customsItem CustomsItem
+	c.Currency = c.Value.Currency
+	return marshalModel(customsItem(c), c.Raw, new(CustomsItem))
 }
 
 type PostageLabel struct {
-	Id              string
-	Object          string
-	Error           string
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	DateAdvance     int64     `json:"date_advance"`
-	IntegratedForm  string    `json:"integrated_form"`
-	LabelDate       time.Time `json:"label_date"`
-	LabelResolution int64     `json:"label_resolution"`
-	LabelSize       string    `json:"label_size"`
-	LabelType       string    `json:"label_type"`
-	LabelFileType   string    `json:"label_file_type"`
-	LabelUrl        string    `json:"label_url"`
-	LabelPDFUrl     string    `json:"label_pdf_url"`
-	LabelEpl2Url    string    `json:"label_epl2_url"`
-	LabelZp1Url     string    `json:"label_zp1_url"`
-	SelectedRate    Rate      `json:"selected_rate"`
+	Id              string          `json:"id,omitempty"`
+	Object          string          `json:"object,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DateAdvance     int64           `json:"date_advance,omitempty"`
+	IntegratedForm  string          `json:"integrated_form,omitempty"`
+	LabelDate       time.Time       `json:"label_date"`
+	LabelResolution int64           `json:"label_resolution,omitempty"`
+	LabelSize       string          `json:"label_size,omitempty"`
+	LabelType       string          `json:"label_type,omitempty"`
+	LabelFileType   string          `json:"label_file_type,omitempty"`
+	LabelUrl        string          `json:"label_url,omitempty"`
+	LabelPDFUrl     string          `json:"label_pdf_url,omitempty"`
+	LabelEpl2Url    string          `json:"label_epl2_url,omitempty"`
+	LabelZp1Url     string          `json:"label_zp1_url,omitempty"`
+	LabelZPLUrl     string          `json:"label_zpl_url,omitempty"`
+	SelectedRate    Rate            `json:"selected_rate"`
+	Raw             json.RawMessage `json:"-"`
+}
+
+func (l *PostageLabel) UnmarshalJSON(data []byte) error {
+	type postageLabel PostageLabel
+	if err := json.Unmarshal(data, (*postageLabel)(l)); err != nil {
+		return err
+	}
+	l.Raw = copyRaw(data)
+	return nil
+}
+
+func (l PostageLabel) MarshalJSON() ([]byte, error) {
+	type postageLabel PostageLabel
+	return marshalModel(postageLabel(l), l.Raw, new(PostageLabel))
 }
 
 type Parcel struct {
-	Id                string
-	Object            string
-	Error             string
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
-	Length            float64
-	Width             float64
-	Height            float64
-	PredefinedPackage string `json:"predefined_package"`
-	Weight            float64
+	Id                string          `json:"id,omitempty"`
+	Object            string          `json:"object,omitempty"`
+	Mode              Mode            `json:"mode,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	Length            Length          `json:"length,omitempty"`
+	Width             Length          `json:"width,omitempty"`
+	Height            Length          `json:"height,omitempty"`
+	PredefinedPackage string          `json:"predefined_package,omitempty"`
+	Weight            Weight          `json:"weight,omitempty"`
+	Raw               json.RawMessage `json:"-"`
+}
+
+func (p *Parcel) UnmarshalJSON(data []byte) error {
+	type parcel Parcel
+	if err := json.Unmarshal(data, (*parcel)(p)); err != nil {
+		return err
+	}
+	p.Raw = copyRaw(data)
+	return nil
+}
+
+func (p Parcel) MarshalJSON() ([]byte, error) {
+	type parcel Parcel
+	return marshalModel(parcel(p), p.Raw, new(Parcel))
 }
 
 type Shipment struct {
-	Id           string
-	Object       string
-	Error        string
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Type         string
-	ToAddress    Address `json:"to_address"`
-	FromAddress  Address `json:"from_address"`
-	Parcel       Parcel
-	CustomsInfo  CustomsInfo `json:"customs_info"`
-	ScanForm     ScanForm    `json:"scan_form"`
-	Rates        []Rate
-	SelectedRate Rate         `json:"selected_rate"`
-	PostageLabel PostageLabel `json:"postage_label"`
-	TrackingCode string       `json:"tracking_code"`
-	Reference    string
-	RefundStatus string `json:"refund_status"`
-	Insurance    float64
-	BatchStatus  string `json:"batch_status"`
-	BatchMessage string `json:"batch_message"`
-	Options      ShippingOptions
+	Id            string            `json:"id,omitempty"`
+	Object        string            `json:"object,omitempty"`
+	Mode          Mode              `json:"mode,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Type          string            `json:"type,omitempty"`
+	Status        TrackingStatus    `json:"status,omitempty"`
+	ToAddress     Address           `json:"to_address"`
+	FromAddress   Address           `json:"from_address"`
+	ReturnAddress Address           `json:"return_address"`
+	BuyerAddress  Address           `json:"buyer_address"`
+	Parcel        Parcel            `json:"parcel"`
+	CustomsInfo   CustomsInfo       `json:"customs_info"`
+	ScanForm      ScanForm          `json:"scan_form"`
+	Forms         []Form            `json:"forms,omitempty"`
+	Rates         []Rate            `json:"rates,omitempty"`
+	SelectedRate  Rate              `json:"selected_rate"`
+	PostageLabel  PostageLabel      `json:"postage_label"`
+	TrackingCode  string            `json:"tracking_code,omitempty"`
+	Reference     string            `json:"reference,omitempty"`
+	RefundStatus  RefundStatus      `json:"refund_status,omitempty"`
+	Insurance     Money             `json:"insurance"` // Always in USD
+	UspsZone      int               `json:"usps_zone,omitempty"`
+	BatchId       string            `json:"batch_id,omitempty"`
+	BatchStatus   string            `json:"batch_status,omitempty"`
+	BatchMessage  string            `json:"batch_message,omitempty"`
+	IsReturn      bool              `json:"is_return,omitempty"`
+	Options       ShippingOptions   `json:"options"`
+	Messages      []ShipmentMessage `json:"messages,omitempty"`
+	Fees          []Fee             `json:"fees,omitempty"`
+	Tracker       Tracker           `json:"tracker"`
+	Carriers      []string          `json:"-"` // See Preflight
+	Raw           json.RawMessage   `json:"-"`
+}
+
+func (s *Shipment) UnmarshalJSON(data []byte) error {
+	type shipment Shipment
+	if err := json.Unmarshal(data, (*shipment)(s)); err != nil {
+		return err
+	}
+	s.Raw = copyRaw(data)
+	return nil
+}
+
+func (s Shipment) MarshalJSON() ([]byte, error) {
+	type shipment Shipment
+	return marshalModel(shipment(s), s.Raw, new(Shipment))
+}
+
+// Form is a document generated for a shipment, such as a commercial invoice
+// or a return packing slip.
+type Form struct {
+	Id                      string          `json:"id,omitempty"`
+	Object                  string          `json:"object,omitempty"`
+	Mode                    Mode            `json:"mode,omitempty"`
+	CreatedAt               time.Time       `json:"created_at"`
+	UpdatedAt               time.Time       `json:"updated_at"`
+	FormType                string          `json:"form_type,omitempty"`
+	FormUrl                 string          `json:"form_url,omitempty"`
+	SubmittedElectronically bool            `json:"submitted_electronically,omitempty"`
+	Raw                     json.RawMessage `json:"-"`
+}
+
+func (f *Form) UnmarshalJSON(data []byte) error {
+	type form Form
+	if err := json.Unmarshal(data, (*form)(f)); err != nil {
+		return err
+	}
+	f.Raw = copyRaw(data)
+	return nil
+}
+
+func (f Form) MarshalJSON() ([]byte, error) {
+	type form Form
+	return marshalModel(form(f), f.Raw, new(Form))
+}
+
+// Fee is one charge for a shipment. A refunded fee is still reported as
+// charged.
+type Fee struct {
+	Object   string          `json:"object,omitempty"`
+	Type     FeeType         `json:"type,omitempty"`
+	Amount   Money           `json:"amount"` // Always in USD
+	Charged  bool            `json:"charged,omitempty"`
+	Refunded bool            `json:"refunded,omitempty"`
+	Raw      json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON fills in the currency of the amount, which the API leaves
+// out, and keeps the JSON in Raw.
+func (f *Fee) UnmarshalJSON(data []byte) error {
+	type fee Fee // Has no UnmarshalJSON method, so no recursion
+	if err := json.Unmarshal(data, (*fee)(f)); err != nil {
+		return err
+	}
+	f.Amount.Currency = "USD"
+	f.Raw = copyRaw(data)
+	return nil
+}
+
+func (f Fee) MarshalJSON() ([]byte, error) {
+	type fee Fee
+	return marshalModel(fee(f), f.Raw, new(Fee))
+}
+
+// ShipmentMessage is a note from a carrier about rating the shipment, most
+// often the reason it returned no rates.
+type ShipmentMessage struct {
+	Carrier          string `json:"carrier,omitempty"`
+	CarrierAccountId string `json:"carrier_account_id,omitempty"`
+	Type             string `json:"type,omitempty"`
+	Message          string `json:"message,omitempty"`
+}
+
+// UnmarshalJSON accepts a message that is an object rather than a string, as
+// some carriers send, keeping its JSON as the text.
+func (m *ShipmentMessage) UnmarshalJSON(data []byte) error {
+	type shipmentMessage ShipmentMessage
+	var aux struct {
+		shipmentMessage
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*m = ShipmentMessage(aux.shipmentMessage)
+	if len(aux.Message) > 0 && json.Unmarshal(aux.Message, &m.Message) != nil {
+		m.Message = string(aux.Message)
+	}
+	return nil
 }
 
 type ShippingOptions struct {
-	AddressValidationLevel string `json:"address_validation_level"`
-	ByDrone                string `json:"by_drone"`
-	Currency               string
-	CarbonNeutral          string `json:"carbon_neutral"`
-	DateAdvance            string `json:"date_advance"`
-	DeclaredValue          string `json:"declared_value"`
-	DeliveryConfirmation   string `json:"delivery_confirmation"`
-	DryIce                 string `json:"dry_ice"`
-	DryIceMedical          string `json:"dry_ice_medical"`
-	DryIceWeight           string `json:"dry_ice_weight"`
-	InvoiceNumber          string `json:"invoice_number"`
-	Machinable             string
-	PoFacility             string `json:"po_facility"`
-	PoZip                  string `json:"po_zip"`
-	PrintCustom1           string `json:"print_custom_1"`
-	PrintCustom2           string `json:"print_custom_2"`
-	PrintCustom3           string `json:"print_custom_3"`
-	ResidentialToAddress   string `json:"residential_to_address"`
-	SaturdayDelivery       string `json:"saturday_delivery"`
-	SmartPostHub           string `json:"smartpost_hub"`
-	SmartPostManifest      string `json:"smartpost_manifest"`
+	AddressValidationLevel string          `json:"address_validation_level,omitempty"`
+	ByDrone                string          `json:"by_drone,omitempty"`
+	Currency               string          `json:"currency,omitempty"`
+	CarbonNeutral          string          `json:"carbon_neutral,omitempty"`
+	DateAdvance            string          `json:"date_advance,omitempty"`
+	DeclaredValue          string          `json:"declared_value,omitempty"`
+	DeliveryConfirmation   string          `json:"delivery_confirmation,omitempty"`
+	DryIce                 string          `json:"dry_ice,omitempty"`
+	DryIceMedical          string          `json:"dry_ice_medical,omitempty"`
+	DryIceWeight           string          `json:"dry_ice_weight,omitempty"`
+	InvoiceNumber          string          `json:"invoice_number,omitempty"`
+	Machinable             string          `json:"machinable,omitempty"`
+	PoFacility             string          `json:"po_facility,omitempty"`
+	PoZip                  string          `json:"po_zip,omitempty"`
+	PrintCustom1           string          `json:"print_custom_1,omitempty"`
+	PrintCustom2           string          `json:"print_custom_2,omitempty"`
+	PrintCustom3           string          `json:"print_custom_3,omitempty"`
+	ResidentialToAddress   string          `json:"residential_to_address,omitempty"`
+	SaturdayDelivery       string          `json:"saturday_delivery,omitempty"`
+	SmartPostHub           string          `json:"smartpost_hub,omitempty"`
+	SmartPostManifest      string          `json:"smartpost_manifest,omitempty"`
+	Raw                    json.RawMessage `json:"-"`
+}
+
+func (o *ShippingOptions) UnmarshalJSON(data []byte) error {
+	type shippingOptions ShippingOptions
+	if err := json.Unmarshal(data, (*shippingOptions)(o)); err != nil {
+		return err
+	}
+	o.Raw = copyRaw(data)
+	return nil
+}
+
+func (o ShippingOptions) MarshalJSON() ([]byte, error) {
+	type shippingOptions ShippingOptions
+	return marshalModel(shippingOptions(o), o.Raw, new(ShippingOptions))
 }
 
 type Batch struct {
-	Id        string
-	Object    string
-	Error     string
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Shipments []Shipment
-	LabelUrl  string `json:"label_url"`
-	Status    BatchStatus
+	Id           string          `json:"id,omitempty"`
+	Object       string          `json:"object,omitempty"`
+	Mode         Mode            `json:"mode,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	State        BatchState      `json:"state,omitempty"`
+	Reference    string          `json:"reference,omitempty"`
+	NumShipments int64           `json:"num_shipments,omitempty"`
+	Shipments    []Shipment      `json:"shipments,omitempty"`
+	LabelUrl     string          `json:"label_url,omitempty"`
+	ScanForm     ScanForm        `json:"scan_form"`
+	Status       BatchStatus     `json:"status"`
+	Raw          json.RawMessage `json:"-"`
+}
+
+func (b *Batch) UnmarshalJSON(data []byte) error {
+	type batch Batch
+	if err := json.Unmarshal(data, (*batch)(b)); err != nil {
+		return err
+	}
+	b.Raw = copyRaw(data)
+	return nil
+}
+
+func (b Batch) MarshalJSON() ([]byte, error) {
+	type batch Batch
+	return marshalModel(batch(b), b.Raw, new(Batch))
 }
 
 type BatchStatus struct {
-	Created                int64
-	PostagePurchased       int64 `json:"postage_purchased"`
-	PostagePurchasedFailed int64 `json:"postage_purchase_failed"`
+	Created                int64 `json:"created,omitempty"`
+	CreationFailed         int64 `json:"creation_failed,omitempty"`
+	QueuedForPurchase      int64 `json:"queued_for_purchase,omitempty"`
+	PostagePurchased       int64 `json:"postage_purchased,omitempty"`
+	PostagePurchasedFailed int64 `json:"postage_purchase_failed,omitempty"`
 }
 
 type Refund struct {
-	Id           string
-	Object       string
-	Error        string
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	TrackingCode string    `json:"tracking_code"`
-	Status       string
-	Carrier      string
-	ShipmentId   string `json:"shipment_id"`
+	Id                 string          `json:"id,omitempty"`
+	Object             string          `json:"object,omitempty"`
+	Mode               Mode            `json:"mode,omitempty"`
+	Error              string          `json:"error,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	TrackingCode       string          `json:"tracking_code,omitempty"`
+	ConfirmationNumber string          `json:"confirmation_number,omitempty"`
+	Status             RefundStatus    `json:"status,omitempty"`
+	Carrier            string          `json:"carrier,omitempty"`
+	ShipmentId         string          `json:"shipment_id,omitempty"`
+	Raw                json.RawMessage `json:"-"`
+}
+
+func (r *Refund) UnmarshalJSON(data []byte) error {
+	type refund Refund
+	if err := json.Unmarshal(data, (*refund)(r)); err != nil {
+		return err
+	}
+	r.Raw = copyRaw(data)
+	return nil
+}
+
+func (r Refund) MarshalJSON() ([]byte, error) {
+	type refund Refund
+	return marshalModel(refund(r), r.Raw, new(Refund))
+}
+
+type Tracker struct {
+	Id              string           `json:"id,omitempty"`
+	Object          string           `json:"object,omitempty"`
+	Mode            Mode             `json:"mode,omitempty"`
+	Error           string           `json:"error,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	TrackingCode    string           `json:"tracking_code,omitempty"`
+	Status          TrackingStatus   `json:"status,omitempty"`
+	StatusDetail    string           `json:"status_detail,omitempty"`
+	SignedBy        string           `json:"signed_by,omitempty"`
+	Weight          Weight           `json:"weight,omitempty"`
+	EstDeliveryDate time.Time        `json:"est_delivery_date"`
+	ShipmentId      string           `json:"shipment_id,omitempty"`
+	Carrier         string           `json:"carrier,omitempty"`
+	PublicUrl       string           `json:"public_url,omitempty"`
+	TrackingDetails []TrackingDetail `json:"tracking_details,omitempty"`
+	Fees            []Fee            `json:"fees,omitempty"`
+	Raw             json.RawMessage  `json:"-"`
+}
+
+func (t *Tracker) UnmarshalJSON(data []byte) error {
+	type tracker Tracker
+	if err := json.Unmarshal(data, (*tracker)(t)); err != nil {
+		return err
+	}
+	t.Raw = copyRaw(data)
+	return nil
+}
+
+func (t Tracker) MarshalJSON() ([]byte, error) {
+	type tracker Tracker
+	return marshalModel(tracker(t), t.Raw, new(Tracker))
+}
+
+type TrackingDetail struct {
+	Object           string           `json:"object,omitempty"`
+	Message          string           `json:"message,omitempty"`
+	Status           TrackingStatus   `json:"status,omitempty"`
+	StatusDetail     string           `json:"status_detail,omitempty"`
+	Datetime         time.Time        `json:"datetime"`
+	Source           string           `json:"source,omitempty"`
+	TrackingLocation TrackingLocation `json:"tracking_location"`
+}
+
+type TrackingLocation struct {
+	Object  string `json:"object,omitempty"`
+	City    string `json:"city,omitempty"`
+	State   string `json:"state,omitempty"`
+	Country string `json:"country,omitempty"`
+	Zip     string `json:"zip,omitempty"`
 }
@@ -0,0 +1,167 @@
+package easypost
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testShipmentJSON = `{
+	"id": "shp_1", "object": "Shipment", "mode": "test", "status": "in_transit",
+	"refund_status": null, "batch_id": "batch_1", "usps_zone": 4,
+	"tracker": null, "scan_form": null,
+	"to_address": {"id": "adr_1", "mode": "test", "residential": true,
+		"carrier_facility": "ONDC", "future_field": "kept"},
+	"forms": [{"object": "Form", "form_type": "commercial_invoice",
+		"form_url": "https://f/1.pdf", "submitted_electronically": true}],
+	"selected_rate": {"id": "rate_1", "carrier": "USPS", "service": "Priority",
+		"rate": "7.25", "list_rate": "8.10", "retail_rate": "9.35", "currency": "USD",
+		"list_currency": "USD", "retail_currency": "USD", "carrier_account_id": "ca_1"},
+	"options": {"hazmat": "LITHIUM", "label_format": "PDF"}
+}`
+
+func TestShipmentModelDecoding(t *testing.T) {
+	var shipment Shipment
+	if err := json.Unmarshal([]byte(testShipmentJSON), &shipment); err != nil {
+		t.Fatal(err)
+	}
+	if shipment.Mode != ModeTest || shipment.Status != TrackingInTransit ||
+		shipment.BatchId != "batch_1" || shipment.UspsZone != 4 || shipment.RefundStatus != "" {
+		t.Errorf("got %+v", shipment)
+	}
+	to := shipment.ToAddress
+	if !to.Residential || to.CarrierFacility != "ONDC" || to.Mode != ModeTest {
+		t.Errorf("to address %+v", to)
+	}
+	if len(shipment.Forms) != 1 || !shipment.Forms[0].SubmittedElectronically {
+		t.Errorf("forms %+v", shipment.Forms)
+	}
+	rate := shipment.SelectedRate
+	if rate.ListRate.String() != "8.10" || rate.RetailRate.Currency != "USD" ||
+		rate.CarrierAccountId != "ca_1" {
+		t.Errorf("selected rate %+v", rate)
+	}
+	if shipment.Tracker.Raw != nil || shipment.ScanForm.Raw != nil {
+		t.Error("null objects kept raw JSON")
+	}
+
+	hazmat, ok := RawField(shipment.Raw, "options", "hazmat")
+	if !ok || string(hazmat) != `"LITHIUM"` {
+		t.Errorf("options.hazmat = %s, %v", hazmat, ok)
+	}
+	future, ok := RawField(to.Raw, "future_field")
+	if !ok || string(future) != `"kept"` {
+		t.Errorf("to_address.future_field = %s, %v", future, ok)
+	}
+	if _, ok = RawField(shipment.Raw, "options", "missing"); ok {
+		t.Error("found a missing field")
+	}
+	if _, ok = RawField(shipment.Raw, "id", "nested"); ok {
+		t.Error("descended into a string")
+	}
+}
+
+func TestTrackingStatusFinal(t *testing.T) {
+	for status, final := range map[TrackingStatus]bool{
+		TrackingPreTransit:     false,
+		TrackingOutForDelivery: false,
+		TrackingDelivered:      true,
+		TrackingReturnToSender: true,
+	} {
+		if status.Final() != final {
+			t.Errorf("%s.Final() = %v", status, !final)
+		}
+	}
+}
+
+func TestShipmentJSONRoundTrip(t *testing.T) {
+	var decoded Shipment
+	if err := json.Unmarshal([]byte(testShipmentJSON), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded.CustomsInfo = CustomsInfo{ContentsExplanation: "Parts", ContentsType: ContentsOther,
+		CustomsItems: []CustomsItem{{Description: "Gear", Quantity: 2,
+			Value: MoneyFromCents(1999, "USD"), Currency: "USD", Weight: Ounces(12)}}}
+	decoded.Fees = []Fee{{Type: FeePostage, Amount: usd(725), Charged: true}}
+	first, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Shipment
+	if err = json.Unmarshal(first, &again); err != nil {
+		t.Fatal(err)
+	}
+	second, err := json.Marshal(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("round trip changed the shipment:\n%s\n%s", first, second)
+	}
+	if again.SelectedRate.ServiceName != "USPS Priority Mail" ||
+		again.CustomsInfo.CustomsItems[0].Value.Cmp(MoneyFromCents(1999, "USD")) != 0 {
+		t.Errorf("decoded %+v", again)
+	}
+
+	var fields map[string]json.RawMessage
+	json.Unmarshal(first, &fields)
+	for _, key := range []string{"id", "to_address", "selected_rate", "customs_info", "usps_zone"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("marshaled shipment has no %q", key)
+		}
+	}
+	for _, key := range []string{"Id", "Raw", "ServiceName", "tracking_code"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("marshaled shipment has %q", key)
+		}
+	}
+	explanation, _ := RawField(first, "customs_info", "contents_explanation")
+	if string(explanation) != `"Parts"` {
+		t.Errorf("contents_explanation = %s", explanation)
+	}
+}
+
+func TestShipmentJSONKeepsAPIFields(t *testing.T) {
+	var shipment Shipment
+	if err := json.Unmarshal([]byte(testShipmentJSON), &shipment); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(shipment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	json.Unmarshal(encoded, &got)
+	json.Unmarshal([]byte(testShipmentJSON), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unchanged shipment encoded as\n%s", encoded)
+	}
+
+	shipment.ToAddress.Name = "Jo Smith"
+	shipment.Options.Currency = "EUR"
+	shipment.UspsZone = 0
+	if encoded, err = json.Marshal(shipment); err != nil {
+		t.Fatal(err)
+	}
+	for path, value := range map[[2]string]string{
+		{"to_address", "name"}:         `"Jo Smith"`,
+		{"to_address", "future_field"}: `"kept"`,
+		{"options", "currency"}:        `"EUR"`,
+		{"options", "hazmat"}:          `"LITHIUM"`,
+		{"selected_rate", "rate"}:      `"7.25"`,
+	} {
+		if field, _ := RawField(encoded, path[0], path[1]); string(field) != value {
+			t.Errorf("%s.%s = %s, want %s", path[0], path[1], field, value)
+		}
+	}
+	if _, ok := RawField(encoded, "usps_zone"); ok {
+		t.Error("cleared usps_zone was kept")
+	}
+
+	if encoded, err = json.Marshal(Shipment{ToAddress: Address{Name: "Jo Smith"}}); err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"to_address":{"name":"Jo Smith"}}` {
+		t.Errorf("new shipment encoded as %s", encoded)
+	}
+}
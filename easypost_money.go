@@ -0,0 +1,236 @@
+package easypost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// moneyScale is the number of fractional digits Money keeps. The API quotes
+// fees with up to five decimal places, so six leaves room to spare.
+const moneyScale = 6
+
+var moneyUnit = int64(math.Pow10(moneyScale))
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are
+// combined.
+var ErrCurrencyMismatch = errors.New("easypost: cannot combine amounts in different currencies")
+
+// ErrMoneyOverflow is returned when arithmetic on amounts would go beyond
+// what Money can hold, about ±9.2 trillion currency units.
+var ErrMoneyOverflow = errors.New("easypost: amount is out of range")
+
+// Money is an exact amount of a currency. The amount is held as a whole
+// number of millionths of the currency unit so that adding up rates, fees and
+// customs values never picks up floating point rounding error. Currency is an
+// ISO 4217 code such as "USD"; an empty Currency combines with any other.
+//
+// In JSON a Money is just its amount, written as a string the way the API
+// does. The currency lives in a separate field of the enclosing object and is
+// copied into the Money when that object is decoded. An amount that is null,
+// empty or missing is not Valid, which tells it apart from "0.00".
+type Money struct {
+	micros   int64
+	valid    bool
+	Currency string
+}
+
+// ParseMoney parses a decimal amount such as "7.25" or "-0.01000". An empty
+// amount gives zero that is not Valid.
+func ParseMoney(amount string, currency string) (Money, error) {
+	m := Money{Currency: currency}
+	s := strings.TrimSpace(amount)
+	if s == "" {
+		return m, nil
+	}
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return m, fmt.Errorf("easypost: invalid amount %q", amount)
+	}
+	if len(frac) > moneyScale {
+		if strings.Trim(frac[moneyScale:], "0") != "" {
+			return m, fmt.Errorf("easypost: amount %q has more than %d decimal places",
+				amount, moneyScale)
+		}
+		frac = frac[:moneyScale]
+	}
+	frac += strings.Repeat("0", moneyScale-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	fraction, _ := strconv.ParseInt(frac, 10, 64)
+	if err != nil || units > math.MaxInt64/moneyUnit ||
+		units == math.MaxInt64/moneyUnit && fraction > math.MaxInt64%moneyUnit {
+		return m, fmt.Errorf("easypost: amount %q is out of range", amount)
+	}
+	m.micros, m.valid = units*moneyUnit+fraction, true
+	if negative {
+		m.micros = -m.micros
+	}
+	return m, nil
+}
+
+// MoneyFromCents returns an amount given in hundredths of the currency unit.
+func MoneyFromCents(cents int64, currency string) Money {
+	return Money{micros: cents * (moneyUnit / 100), valid: true, Currency: currency}
+}
+
+// MoneyFromFloat converts f to Money, rounding to the nearest millionth.
+func MoneyFromFloat(f float64, currency string) Money {
+	return Money{micros: int64(math.Round(f * float64(moneyUnit))), valid: true,
+		Currency: currency}
+}
+
+// IsZero reports whether the amount is zero, whether or not it is Valid.
+func (m Money) IsZero() bool {
+	return m.micros == 0
+}
+
+// Valid reports whether the amount was given: parsed from a number, made by
+// MoneyFromCents or MoneyFromFloat, or computed from a valid amount. A rate
+// whose price the API left out is not Valid, while one of "0.00" is.
+func (m Money) Valid() bool {
+	return m.valid
+}
+
+// Float64 returns the amount as a float64. The result is only approximate
+// and shouldn't be used for further arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.micros) / float64(moneyUnit)
+}
+
+// Add returns m + o. It fails if the amounts are in different currencies
+// or the sum is out of range.
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.commonCurrency(o)
+	sum := m.micros + o.micros
+	if err == nil && (o.micros > 0 && sum < m.micros || o.micros < 0 && sum > m.micros) {
+		err = ErrMoneyOverflow
+	}
+	return Money{micros: sum, valid: m.valid || o.valid, Currency: currency}, err
+}
+
+// Sub returns m - o. It fails if the amounts are in different currencies
+// or the difference is out of range.
+func (m Money) Sub(o Money) (Money, error) {
+	currency, err := m.commonCurrency(o)
+	difference := m.micros - o.micros
+	if err == nil && (o.micros < 0 && difference < m.micros ||
+		o.micros > 0 && difference > m.micros) {
+		err = ErrMoneyOverflow
+	}
+	return Money{micros: difference, valid: m.valid || o.valid, Currency: currency},
+		err
+}
+
+// Mul returns m multiplied by n, e.g. a unit price times a quantity. It
+// fails with ErrMoneyOverflow if the product is out of range.
+func (m Money) Mul(n int64) (Money, error) {
+	product := m.micros * n
+	if n != 0 && (product/n != m.micros || n == -1 && m.micros == math.MinInt64) {
+		return Money{Currency: m.Currency}, ErrMoneyOverflow
+	}
+	return Money{micros: product, valid: m.valid, Currency: m.Currency}, nil
+}
+
+// Cmp compares the amounts of m and o, returning -1, 0 or +1. Currencies are
+// not taken into account.
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.micros < o.micros:
+		return -1
+	case m.micros > o.micros:
+		return 1
+	}
+	return 0
+}
+
+// SumMoney adds up amounts, failing if they are in different currencies or
+// the total is out of range.
+func SumMoney(amounts ...Money) (total Money, err error) {
+	for _, amount := range amounts {
+		if total, err = total.Add(amount); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount with at least two decimal places, e.g. "7.25" or
+// "0.0125". The currency is not included.
+func (m Money) String() string {
+	micros := m.micros
+	sign := ""
+	if micros < 0 {
+		sign = "-"
+		micros = -micros
+	}
+	frac := fmt.Sprintf("%0*d", moneyScale, micros%moneyUnit)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return sign + strconv.FormatInt(micros/moneyUnit, 10) + "." + frac
+}
+
+// MarshalJSON writes an amount that is not Valid as null.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.valid {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON accepts the amount as either a string or a number, as the API
+// uses both. null and "" decode to zero. The currency is left unchanged.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		m.micros, m.valid = 0, false
+		return nil
+	}
+	amount := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		var err error
+		if amount, err = strconv.Unquote(amount); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(amount, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) commonCurrency(o Money) (string, error) {
+	switch {
+	case m.Currency == "":
+		return o.Currency, nil
+	case o.Currency == "" || strings.EqualFold(m.Currency, o.Currency):
+		return m.Currency, nil
+	}
+	return m.Currency, ErrCurrencyMismatch
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
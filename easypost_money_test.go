@@ -0,0 +1,122 @@
+package easypost
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+// usd returns an amount in US dollars given in cents.
+func usd(cents int64) Money {
+	return MoneyFromCents(cents, "USD")
+}
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]string{
+		"7.25":     "7.25",
+		"7":        "7.00",
+		".5":       "0.50",
+		"-0.01000": "-0.01",
+		"0.012500": "0.0125",
+		" 12.3 ":   "12.30",
+	}
+	for in, want := range cases {
+		m, err := ParseMoney(in, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.String() != want {
+			t.Fatalf("ParseMoney(%q) = %s, want %s", in, m, want)
+		}
+	}
+	for _, in := range []string{"abc", "1.2.3", ".", "1.0000001", "1e3"} {
+		if _, err := ParseMoney(in, "USD"); err == nil {
+			t.Fatalf("ParseMoney(%q) should fail", in)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	total := Money{}
+	for i := 0; i < 10; i++ {
+		var err error
+		if total, err = total.Add(usd(10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total.Cmp(usd(100)) != 0 || total.Currency != "USD" {
+		t.Fatalf("ten cents ten times is %s %s", total, total.Currency)
+	}
+	if _, err := usd(1).Add(MoneyFromCents(1, "CAD")); err != ErrCurrencyMismatch {
+		t.Fatal("adding USD to CAD should fail")
+	}
+	if product, err := usd(250).Mul(3); err != nil || product.String() != "7.50" {
+		t.Fatalf("2.50 times 3 is %s, %v", product, err)
+	}
+}
+
+func TestMoneyOverflow(t *testing.T) {
+	largest, err := ParseMoney("9223372036854.775807", "USD")
+	if err != nil || largest.String() != "9223372036854.775807" {
+		t.Fatalf("largest amount parsed as %s, %v", largest, err)
+	}
+	for _, in := range []string{"9223372036854.775808", "9223372036854.999999",
+		"-9223372036854.999999", "9223372036855"} {
+		if m, err := ParseMoney(in, "USD"); err == nil {
+			t.Errorf("ParseMoney(%q) = %s, want an error", in, m)
+		}
+	}
+	if _, err = largest.Add(usd(1)); err != ErrMoneyOverflow {
+		t.Errorf("Add past the largest amount returned %v", err)
+	}
+	negative, _ := ParseMoney("-9223372036854.775807", "USD")
+	if _, err = negative.Sub(usd(1)); err != ErrMoneyOverflow {
+		t.Errorf("Sub past the smallest amount returned %v", err)
+	}
+	if _, err = usd(1999).Mul(1 << 40); err != ErrMoneyOverflow {
+		t.Errorf("Mul past the largest amount returned %v", err)
+	}
+	if product, err := usd(1999).Mul(-2); err != nil || product.String() != "-39.98" {
+		t.Errorf("19.99 times -2 is %s, %v", product, err)
+	}
+
+	builder := CustomsBuilder{CustomsSigner: "Jo"}
+	builder.Add(LineItem{Description: "Bulk", Quantity: 1 << 40, UnitValue: usd(1999),
+		UnitWeight: Ounces(1), OriginCountry: "US"})
+	_, err = builder.Build()
+	errs, ok := err.(ValidationErrors)
+	if !ok || errs[len(errs)-1].Field != "customs_items[0].value" {
+		t.Errorf("an overflowing quantity returned %v", err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var rate Rate
+	err := json.Unmarshal([]byte(`{"rate": "7.25", "currency": "USD",
+		"list_rate": 8.1, "list_currency": "USD", "retail_rate": null}`), &rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.Rate.Cmp(usd(725)) != 0 || rate.Rate.Currency != "USD" ||
+		rate.ListRate.Cmp(usd(810)) != 0 || !rate.RetailRate.IsZero() ||
+		!rate.Rate.Valid() || rate.RetailRate.Valid() {
+		t.Fatalf("decoded %+v", rate)
+	}
+	encoded, _ := json.Marshal(rate.Rate)
+	if string(encoded) != `"7.25"` {
+		t.Fatal("encoded as " + string(encoded))
+	}
+
+	rate.Rate = MoneyFromCents(990, "CAD")
+	for _, r := range []Rate{rate, {Rate: usd(725)}} {
+		encoded, _ = json.Marshal(r)
+		currency, _ := RawField(encoded, "currency")
+		if string(currency) != strconv.Quote(r.Rate.Currency) {
+			t.Errorf("rate in %s encoded as %s", r.Rate.Currency, encoded)
+		}
+	}
+	encoded, _ = json.Marshal(CustomsItem{Value: MoneyFromCents(500, "EUR")})
+	if currency, _ := RawField(encoded, "currency"); string(currency) != `"EUR"` {
+		t.Errorf("customs item encoded as %s", encoded)
+	}
+}
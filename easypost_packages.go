@@ -0,0 +1,143 @@
+package easypost
+
+import (
+	"strings"
+	"sync"
+)
+
+// PredefinedPackage is a carrier's own packaging, such as a USPS flat rate
+// box. Name is the value sent to the API as predefined_package. The
+// dimensions are the nominal outside size; envelopes and paks have no height.
+type PredefinedPackage struct {
+	Carrier string
+	Name    string
+	Length  Length
+	Width   Length
+	Height  Length
+}
+
+var (
+	USPSCard                     = PredefinedPackage{"USPS", "Card", Inches(6), Inches(4.25), Inches(0)}
+	USPSLetter                   = PredefinedPackage{"USPS", "Letter", Inches(11.5), Inches(6.125), Inches(0)}
+	USPSFlat                     = PredefinedPackage{"USPS", "Flat", Inches(15), Inches(12), Inches(0)}
+	USPSFlatRateEnvelope         = PredefinedPackage{"USPS", "FlatRateEnvelope", Inches(12.5), Inches(9.5), Inches(0)}
+	USPSFlatRateLegalEnvelope    = PredefinedPackage{"USPS", "FlatRateLegalEnvelope", Inches(15), Inches(9.5), Inches(0)}
+	USPSFlatRatePaddedEnvelope   = PredefinedPackage{"USPS", "FlatRatePaddedEnvelope", Inches(12.5), Inches(9.5), Inches(0)}
+	USPSSmallFlatRateEnvelope    = PredefinedPackage{"USPS", "SmallFlatRateEnvelope", Inches(10), Inches(6), Inches(0)}
+	USPSFlatRateWindowEnvelope   = PredefinedPackage{"USPS", "FlatRateWindowEnvelope", Inches(10), Inches(5), Inches(0)}
+	USPSFlatRateGiftCardEnvelope = PredefinedPackage{"USPS", "FlatRateGiftCardEnvelope", Inches(10), Inches(7), Inches(0)}
+	USPSSmallFlatRateBox         = PredefinedPackage{"USPS", "SmallFlatRateBox", Inches(8.625), Inches(5.375), Inches(1.625)}
+	USPSMediumFlatRateBox        = PredefinedPackage{"USPS", "MediumFlatRateBox", Inches(11), Inches(8.5), Inches(5.5)}
+	USPSLargeFlatRateBox         = PredefinedPackage{"USPS", "LargeFlatRateBox", Inches(12), Inches(12), Inches(5.5)}
+	USPSRegionalRateBoxA         = PredefinedPackage{"USPS", "RegionalRateBoxA", Inches(10), Inches(7), Inches(4.75)}
+	USPSRegionalRateBoxB         = PredefinedPackage{"USPS", "RegionalRateBoxB", Inches(12), Inches(10.25), Inches(5)}
+
+	UPSLetter           = PredefinedPackage{"UPS", "UPSLetter", Inches(12.5), Inches(9.5), Inches(0)}
+	UPSPak              = PredefinedPackage{"UPS", "Pak", Inches(16), Inches(12.75), Inches(0)}
+	UPSTube             = PredefinedPackage{"UPS", "Tube", Inches(38), Inches(6), Inches(6)}
+	UPSExpressBox       = PredefinedPackage{"UPS", "UPSExpressBox", Inches(18), Inches(13), Inches(3)}
+	UPSSmallExpressBox  = PredefinedPackage{"UPS", "SmallExpressBox", Inches(13), Inches(11), Inches(2)}
+	UPSMediumExpressBox = PredefinedPackage{"UPS", "MediumExpressBox", Inches(16), Inches(11), Inches(3)}
+	UPSLargeExpressBox  = PredefinedPackage{"UPS", "LargeExpressBox", Inches(18), Inches(13), Inches(3)}
+	UPS10kgBox          = PredefinedPackage{"UPS", "UPS10kgBox", Inches(16.5), Inches(13.25), Inches(10.75)}
+	UPS25kgBox          = PredefinedPackage{"UPS", "UPS25kgBox", Inches(19.375), Inches(17.375), Inches(14)}
+
+	FedExEnvelope  = PredefinedPackage{"FedEx", "FedExEnvelope", Inches(12.5), Inches(9.5), Inches(0)}
+	FedExPak       = PredefinedPackage{"FedEx", "FedExPak", Inches(15.5), Inches(12), Inches(0)}
+	FedExTube      = PredefinedPackage{"FedEx", "FedExTube", Inches(38), Inches(6), Inches(6)}
+	FedExSmallBox  = PredefinedPackage{"FedEx", "FedExSmallBox", Inches(12.375), Inches(10.875), Inches(1.5)}
+	FedExMediumBox = PredefinedPackage{"FedEx", "FedExMediumBox", Inches(13.25), Inches(11.5), Inches(2.375)}
+	FedExLargeBox  = PredefinedPackage{"FedEx", "FedExLargeBox", Inches(17.5), Inches(12.375), Inches(3)}
+	FedEx10kgBox   = PredefinedPackage{"FedEx", "FedEx10kgBox", Inches(15.81), Inches(12.94), Inches(10.19)}
+	FedEx25kgBox   = PredefinedPackage{"FedEx", "FedEx25kgBox", Inches(21.56), Inches(16.56), Inches(13.19)}
+)
+
+var predefinedPackagesMu sync.RWMutex
+
+var predefinedPackages = []PredefinedPackage{
+	USPSCard, USPSLetter, USPSFlat, USPSFlatRateEnvelope,
+	USPSFlatRateLegalEnvelope, USPSFlatRatePaddedEnvelope,
+	USPSSmallFlatRateEnvelope, USPSFlatRateWindowEnvelope,
+	USPSFlatRateGiftCardEnvelope, USPSSmallFlatRateBox, USPSMediumFlatRateBox,
+	USPSLargeFlatRateBox, USPSRegionalRateBoxA, USPSRegionalRateBoxB,
+	UPSLetter, UPSPak, UPSTube, UPSExpressBox, UPSSmallExpressBox,
+	UPSMediumExpressBox, UPSLargeExpressBox, UPS10kgBox, UPS25kgBox,
+	FedExEnvelope, FedExPak, FedExTube, FedExSmallBox, FedExMediumBox,
+	FedExLargeBox, FedEx10kgBox, FedEx25kgBox,
+}
+
+// RegisterPredefinedPackage adds a package to the catalog, for carriers or
+// packaging the library doesn't know about yet.
+func RegisterPredefinedPackage(pkg PredefinedPackage) {
+	predefinedPackagesMu.Lock()
+	defer predefinedPackagesMu.Unlock()
+	predefinedPackages = append(predefinedPackages, pkg)
+}
+
+// PredefinedPackages returns the packages offered by carrier.
+func PredefinedPackages(carrier string) []PredefinedPackage {
+	predefinedPackagesMu.RLock()
+	defer predefinedPackagesMu.RUnlock()
+	var packages []PredefinedPackage
+	for _, pkg := range predefinedPackages {
+		if strings.EqualFold(pkg.Carrier, carrier) {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// LookupPredefinedPackage returns carrier's package with the given name.
+func LookupPredefinedPackage(carrier string, name string) (
+	pkg PredefinedPackage, ok bool) {
+	predefinedPackagesMu.RLock()
+	defer predefinedPackagesMu.RUnlock()
+	for _, pkg := range predefinedPackages {
+		if strings.EqualFold(pkg.Carrier, carrier) && pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return pkg, false
+}
+
+// Parcel returns a parcel of the given weight using the package, with the
+// package's nominal dimensions.
+func (pkg PredefinedPackage) Parcel(weight Weight) Parcel {
+	return Parcel{PredefinedPackage: pkg.Name, Length: pkg.Length,
+		Width: pkg.Width, Height: pkg.Height, Weight: weight}
+}
+
+// predefinedPackageNamed returns the first package in the catalog with the
+// given name, whichever carrier offers it.
+func predefinedPackageNamed(name string) (pkg PredefinedPackage, ok bool) {
+	predefinedPackagesMu.RLock()
+	defer predefinedPackagesMu.RUnlock()
+	for _, pkg := range predefinedPackages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return pkg, false
+}
+
+// isPredefinedPackage reports whether any carrier offers a package by name.
+func isPredefinedPackage(name string) bool {
+	_, ok := predefinedPackageNamed(name)
+	return ok
+}
+
+// ValidateForCarriers checks that the parcel's predefined package, if it has
+// one, is offered by every one of the carriers it will be rated with.
+func (parc *Parcel) ValidateForCarriers(carriers ...string) error {
+	if parc.PredefinedPackage == "" {
+		return nil
+	}
+	var errs ValidationErrors
+	for _, carrier := range carriers {
+		if _, ok := LookupPredefinedPackage(carrier, parc.PredefinedPackage); !ok {
+			errs = append(errs, FieldError{Field: "predefined_package",
+				Message: parc.PredefinedPackage + " is not offered by " + carrier})
+		}
+	}
+	return errs.err()
+}
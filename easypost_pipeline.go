@@ -0,0 +1,201 @@
+package easypost
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// Stages of a Pipeline, as reported in PipelineError.
+const (
+	StageCreate = "create"
+	StageSelect = "select"
+	StageBuy    = "buy"
+)
+
+// Pipeline creates shipments, selects a rate for each with Policy and buys
+// the label, running up to Workers shipments at a time. A shipment that
+// already has an Id is not created again, only rated and bought; its rates
+// are retrieved if it has none.
+type Pipeline struct {
+	// Workers is the number of shipments processed at once. Defaults to 4.
+	Workers int
+	Policy  RatePolicy
+	// NoBuy stops each shipment after its rate is selected, so that the
+	// selection can be reviewed before anything is bought.
+	NoBuy bool
+}
+
+// PipelineResult is the outcome for one shipment. Index is its position in
+// the input. Shipment holds as much as was done before any error, e.g. a
+// created shipment whose purchase failed.
+type PipelineResult struct {
+	Index    int
+	Shipment Shipment
+	Err      error
+}
+
+// PipelineError reports the stage at which a shipment failed.
+type PipelineError struct {
+	Index int
+	Stage string
+	Err   error
+}
+
+func (e *PipelineError) Error() string {
+	return "shipment " + strconv.Itoa(e.Index) + ": " + e.Stage + ": " + e.Err.Error()
+}
+
+type pipelineJob struct {
+	index    int
+	shipment Shipment
+}
+
+// Run processes the shipments received from in until it is closed and sends
+// a result for each on the returned channel, in input order. An error for
+// one shipment doesn't stop the others.
+//
+// When ctx is cancelled no further shipments are read or started, API calls
+// in progress are abandoned, and shipments read but not finished get
+// ctx.Err(). A call whose response has already arrived is kept, so a result
+// may still hold a label bought just before cancellation. The returned
+// channel is closed once every result is sent, and must be read until then.
+func (p *Pipeline) Run(ctx context.Context, in <-chan Shipment) <-chan PipelineResult {
+	workers := p.Workers
+	if workers < 1 {
+		workers = 4
+	}
+	jobs := make(chan pipelineJob)
+	done := make(chan PipelineResult)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for index := 0; ; index++ {
+			var shipment Shipment
+			var ok bool
+			select {
+			case shipment, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- pipelineJob{index, shipment}:
+			case <-ctx.Done():
+				done <- PipelineResult{Index: index, Shipment: shipment,
+					Err: &PipelineError{Index: index, Stage: StageCreate, Err: ctx.Err()}}
+				return
+			}
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				shipment, err := p.process(ctx, job.index, job.shipment)
+				done <- PipelineResult{Index: job.index, Shipment: shipment, Err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	out := make(chan PipelineResult)
+	go func() {
+		defer close(out)
+		pending := map[int]PipelineResult{}
+		next := 0
+		for result := range done {
+			pending[result.Index] = result
+			for {
+				result, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				out <- result
+				next++
+			}
+		}
+	}()
+	return out
+}
+
+// RunAll runs the pipeline over shipments and returns the results in the
+// same order.
+func (p *Pipeline) RunAll(ctx context.Context, shipments []Shipment) []PipelineResult {
+	in := make(chan Shipment)
+	go func() {
+		defer close(in)
+		for _, shipment := range shipments {
+			select {
+			case in <- shipment:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	results := make([]PipelineResult, 0, len(shipments))
+	for result := range p.Run(ctx, in) {
+		results = append(results, result)
+	}
+	// Shipments never read because of cancellation still get a result.
+	for index := len(results); index < len(shipments); index++ {
+		results = append(results, PipelineResult{Index: index,
+			Shipment: shipments[index],
+			Err:      &PipelineError{Index: index, Stage: StageCreate, Err: ctx.Err()}})
+	}
+	return results
+}
+
+func (p *Pipeline) process(ctx context.Context, index int, shipment Shipment) (
+	Shipment, error) {
+	fail := func(stage string, err error) (Shipment, error) {
+		return shipment, &PipelineError{Index: index, Stage: stage, Err: err}
+	}
+	if ctx.Err() != nil {
+		return fail(StageCreate, ctx.Err())
+	}
+	if shipment.Id == "" {
+		created, err := newShipmentContext(ctx, &shipment)
+		if err != nil {
+			return fail(StageCreate, err)
+		}
+		shipment = created
+	} else if len(shipment.Rates) == 0 {
+		rates, err := retrieveRatesContext(ctx, shipment.Id)
+		if err != nil {
+			return fail(StageSelect, err)
+		}
+		shipment.Rates = rates
+	}
+
+	if ctx.Err() != nil {
+		return fail(StageSelect, ctx.Err())
+	}
+	best, _, err := p.Policy.Select(shipment.Rates)
+	if err != nil {
+		return fail(StageSelect, err)
+	}
+	shipment.SelectedRate = best
+	if p.NoBuy {
+		return shipment, nil
+	}
+
+	if ctx.Err() != nil {
+		return fail(StageBuy, ctx.Err())
+	}
+	bought, err := buyShippingLabelContext(ctx, shipment.Id, best.Id)
+	if err != nil {
+		return fail(StageBuy, err)
+	}
+	return bought, nil
+}
@@ -0,0 +1,90 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPipelineOrderAndErrors(t *testing.T) {
+	shipments := make([]Shipment, 50)
+	for i := range shipments {
+		shipments[i] = Shipment{Id: "shp_" + strconv.Itoa(i), Rates: testRates}
+	}
+	// Nothing acceptable for this one; the rest must be unaffected. Having
+	// no rates, they are retrieved first.
+	shipments[7].Rates = nil
+	var requests []string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"rates": []}`))
+	})()
+
+	p := Pipeline{Workers: 8, NoBuy: true}
+	results := p.RunAll(context.Background(), shipments)
+	if len(results) != len(shipments) {
+		t.Fatalf("got %d results, want %d", len(results), len(shipments))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Shipment.Id != shipments[i].Id {
+			t.Fatalf("result %d is for %s (index %d)", i, result.Shipment.Id, result.Index)
+		}
+		if i == 7 {
+			pipelineErr, ok := result.Err.(*PipelineError)
+			if !ok || pipelineErr.Stage != StageSelect || pipelineErr.Err != ErrNoRate {
+				t.Errorf("result 7: got error %v", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil || result.Shipment.SelectedRate.Id != "rate_2" {
+			t.Errorf("result %d: selected %q, error %v", i,
+				result.Shipment.SelectedRate.Id, result.Err)
+		}
+	}
+	if len(requests) != 1 || requests[0] != "GET /shipments/shp_7/rates" {
+		t.Errorf("sent %q", requests)
+	}
+}
+
+func TestPipelineCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	shipments := []Shipment{{Id: "shp_1", Rates: testRates}, {Id: "shp_2", Rates: testRates}}
+	results := (&Pipeline{NoBuy: true}).RunAll(ctx, shipments)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for i, result := range results {
+		pipelineErr, ok := result.Err.(*PipelineError)
+		if !ok || pipelineErr.Err != context.Canceled {
+			t.Errorf("result %d: got error %v", i, result.Err)
+		}
+	}
+}
+
+func TestPipelineCancelsRequests(t *testing.T) {
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body
+		// has been read.
+		readForm(r)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			w.Write([]byte(`{"id": "shp_1", "tracking_code": "9400"}`))
+		}
+	})()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	results := (&Pipeline{}).RunAll(ctx, []Shipment{{Id: "shp_1", Rates: testRates}})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the purchase wasn't abandoned; took %v", elapsed)
+	}
+	pipelineErr, ok := results[0].Err.(*PipelineError)
+	if !ok || pipelineErr.Stage != StageBuy || pipelineErr.Err != context.Canceled {
+		t.Errorf("got error %v", results[0].Err)
+	}
+}
@@ -0,0 +1,126 @@
+package easypost
+
+import (
+	"strings"
+)
+
+// usTerritories are the US territories and freely associated states that
+// have their own country codes but are addressed like US states.
+var usTerritories = map[string]bool{
+	"AS": true, "FM": true, "GU": true, "MH": true, "MP": true, "PR": true,
+	"PW": true, "UM": true, "VI": true,
+}
+
+// militaryStates are the "states" used for APO, FPO and DPO addresses.
+var militaryStates = map[string]bool{"AA": true, "AE": true, "AP": true}
+
+// CustomsUndetermined is the reason RequiresCustoms gives when it can't tell
+// whether customs is needed.
+const CustomsUndetermined = "an address is given only by Id, so its country is unknown"
+
+// RequiresCustoms reports whether the shipment needs a customs declaration:
+// when it crosses a border, or goes to or from a US territory or a military
+// APO, FPO or DPO address. reason says why. An address given only by Id,
+// without its Country, can't be checked; then required is false and reason
+// is CustomsUndetermined.
+func (s *Shipment) RequiresCustoms() (required bool, reason string) {
+	if !addressKnown(&s.ToAddress) || !addressKnown(&s.FromAddress) {
+		return false, CustomsUndetermined
+	}
+	to, from := addressCountry(&s.ToAddress), addressCountry(&s.FromAddress)
+	switch {
+	case to != from:
+		return true, "shipment from " + from + " to " + to + " is international"
+	case isMilitaryAddress(&s.ToAddress):
+		return true, "shipment is to a military address"
+	case isMilitaryAddress(&s.FromAddress):
+		return true, "shipment is from a military address"
+	case usTerritories[to]:
+		return true, "shipment is within the US territory " + to
+	}
+	return false, ""
+}
+
+// HasCustoms reports whether a customs declaration has been attached.
+func (s *Shipment) HasCustoms() bool {
+	return s.CustomsInfo.Id != "" || len(s.CustomsInfo.CustomsItems) > 0
+}
+
+// Preflight checks the shipment locally before it is created: a parcel not
+// given by Id must be valid, and a customs declaration must be attached if
+// RequiresCustoms says one is needed. Whether customs is needed isn't checked
+// for addresses given only by Id without their Country, so set Country on
+// those to have it checked. If the carriers the shipment will be rated with
+// are given, or else set in s.Carriers, its predefined package must be
+// offered by each of them. NewShipment, QuoteRates and NewBatch call it
+// automatically, so set Carriers to have the package checked there.
+func (s *Shipment) Preflight(carriers ...string) error {
+	if len(carriers) == 0 {
+		carriers = s.Carriers
+	}
+	var errs ValidationErrors
+	if s.Parcel.Id == "" {
+		if err := s.Parcel.Validate(); err != nil {
+			errs = append(errs, withPrefix(err, "parcel.").(ValidationErrors)...)
+		}
+		if err := s.Parcel.ValidateForCarriers(carriers...); err != nil {
+			errs = append(errs, withPrefix(err, "parcel.").(ValidationErrors)...)
+		}
+	}
+	if required, reason := s.RequiresCustoms(); required && !s.HasCustoms() {
+		errs = append(errs, FieldError{Field: "customs_info",
+			Message: "is required because the " + reason})
+	}
+	return errs.err()
+}
+
+// PrepareCustoms attaches a customs declaration built from builder if the
+// shipment requires one and doesn't have one yet. The declaration is created
+// with NewCustomsInfo so that it can be reused, e.g. for a return.
+func (s *Shipment) PrepareCustoms(builder *CustomsBuilder) error {
+	if required, _ := s.RequiresCustoms(); !required || s.HasCustoms() {
+		return nil
+	}
+	customsInfo, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	s.CustomsInfo, err = NewCustomsInfo(&customsInfo)
+	return err
+}
+
+// addressKnown reports whether enough of an address is present to tell where
+// it is.
+func addressKnown(addr *Address) bool {
+	return addr.Id == "" || addr.Country != ""
+}
+
+// addressCountry returns the address's country code, treating a US address
+// with a territory's state code as being in that territory and an empty
+// country as the United States.
+func addressCountry(addr *Address) string {
+	country := "US"
+	if addr.Country != "" {
+		if code, ok := NormalizeCountry(addr.Country); ok {
+			country = code
+		} else {
+			country = strings.ToUpper(addr.Country)
+		}
+	}
+	if state := strings.ToUpper(strings.TrimSpace(addr.State)); country == "US" &&
+		usTerritories[state] {
+		return state
+	}
+	return country
+}
+
+func isMilitaryAddress(addr *Address) bool {
+	if militaryStates[strings.ToUpper(strings.TrimSpace(addr.State))] {
+		return true
+	}
+	switch strings.ToUpper(strings.TrimSpace(addr.City)) {
+	case "APO", "FPO", "DPO":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package easypost
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter, if set, paces every API request. It is nil by default, so
+// requests are sent as fast as they are made.
+//
+//	easypost.Limiter = easypost.NewRateLimiter(easypost.Limit{Rate: 10, Burst: 20})
+//	easypost.Limiter.SetLimit(easypost.ClassBuy, easypost.Limit{Rate: 2, Burst: 2})
+var Limiter *RateLimiter
+
+// Endpoint classes that can be given their own limit with SetLimit. The
+// global limit applies to all of them as well.
+const (
+	ClassGlobal = ""
+	ClassBuy    = "buy"
+	ClassBatch  = "batch"
+)
+
+// Limit is a sustained rate in requests per second and the number of
+// requests that may be made at once after a quiet spell.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimiterState describes one bucket of a RateLimiter. Rate is below the
+// configured Limit.Rate while the limiter is slowed down after a 429.
+type LimiterState struct {
+	Class        string
+	Limit        Limit
+	Rate         float64
+	Tokens       float64
+	BlockedUntil time.Time
+	Throttled    int
+}
+
+// RateLimiter is a token bucket limiter with a global bucket and a bucket
+// per endpoint class. A request takes a token from the global bucket and
+// from its class's bucket, if the class has one.
+//
+// When the API answers 429 Too Many Requests both buckets are slowed to half
+// their current rate, down to a sixteenth of the limit, and paused for the
+// Retry-After the API gives. Each later success restores a twentieth of the
+// limit.
+type RateLimiter struct {
+	// MaxRetries is how many times a request answered with 429 is sent
+	// again. NewRateLimiter sets it to 3.
+	MaxRetries int
+	// Classify returns the endpoint class of a request path. It defaults
+	// to EndpointClass.
+	Classify func(path string) string
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+type tokenBucket struct {
+	limit        Limit
+	rate         float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	throttled    int
+}
+
+// NewRateLimiter returns a limiter with the given global limit.
+func NewRateLimiter(global Limit) *RateLimiter {
+	l := &RateLimiter{
+		MaxRetries: 3,
+		buckets:    map[string]*tokenBucket{},
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+	l.SetLimit(ClassGlobal, global)
+	return l
+}
+
+// EndpointClass returns ClassBuy for label, insurance and pickup purchases,
+// ClassBatch for batch endpoints and ClassGlobal for everything else.
+func EndpointClass(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	switch {
+	case strings.HasSuffix(path, "/buy") || strings.HasSuffix(path, "/insure"):
+		return ClassBuy
+	case strings.HasPrefix(path, "/batches"):
+		return ClassBatch
+	}
+	return ClassGlobal
+}
+
+// SetLimit sets the limit of an endpoint class, or the global limit for
+// ClassGlobal. A zero Rate removes the class's limit.
+func (l *RateLimiter) SetLimit(class string, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit.Rate <= 0 {
+		delete(l.buckets, class)
+		return
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	l.buckets[class] = &tokenBucket{limit: limit, rate: limit.Rate,
+		tokens: float64(limit.Burst), last: l.now()}
+}
+
+// Wait blocks until a request to path may be made, and takes its tokens.
+// If ctx is done first the tokens are given back and ctx.Err() is returned.
+func (l *RateLimiter) Wait(ctx context.Context, path string) error {
+	if err := l.sleep(ctx, l.reserve(path)); err != nil {
+		l.release(path)
+		return err
+	}
+	return nil
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Delay returns how long a request to path would wait now, without taking
+// any tokens.
+func (l *RateLimiter) Delay(path string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var delay time.Duration
+	for _, b := range l.bucketsFor(path) {
+		b.refill(now)
+		if d := b.wait(now, b.tokens-1); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+func (l *RateLimiter) reserve(path string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var delay time.Duration
+	for _, b := range l.bucketsFor(path) {
+		b.refill(now)
+		b.tokens--
+		if d := b.wait(now, b.tokens); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// release gives back the tokens reserve took for a request that was not
+// made.
+func (l *RateLimiter) release(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for _, b := range l.bucketsFor(path) {
+		b.refill(now)
+		if b.tokens++; b.tokens > float64(b.limit.Burst) {
+			b.tokens = float64(b.limit.Burst)
+		}
+	}
+}
+
+// Observe records the response to a request to path and reports whether it
+// was throttled. retryAfter is the Retry-After header, in seconds, if any.
+func (l *RateLimiter) Observe(path string, status int, retryAfter string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	throttled := status == 429
+	pause := time.Second
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil {
+		pause = time.Duration(seconds) * time.Second
+	}
+	for _, b := range l.bucketsFor(path) {
+		b.refill(now)
+		if throttled {
+			b.throttled++
+			b.rate /= 2
+			if floor := b.limit.Rate / 16; b.rate < floor {
+				b.rate = floor
+			}
+			if until := now.Add(pause); until.After(b.blockedUntil) {
+				b.blockedUntil = until
+			}
+		} else if b.rate < b.limit.Rate {
+			b.rate += b.limit.Rate / 20
+			if b.rate > b.limit.Rate {
+				b.rate = b.limit.Rate
+			}
+		}
+	}
+	return throttled
+}
+
+// State returns the state of every bucket, the global one first.
+func (l *RateLimiter) State() []LimiterState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	states := make([]LimiterState, 0, len(l.buckets))
+	for class, b := range l.buckets {
+		b.refill(now)
+		states = append(states, LimiterState{
+			Class:        class,
+			Limit:        b.limit,
+			Rate:         b.rate,
+			Tokens:       b.tokens,
+			BlockedUntil: b.blockedUntil,
+			Throttled:    b.throttled,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Class < states[j].Class
+	})
+	return states
+}
+
+func (l *RateLimiter) bucketsFor(path string) []*tokenBucket {
+	classify := l.Classify
+	if classify == nil {
+		classify = EndpointClass
+	}
+	var buckets []*tokenBucket
+	if b, ok := l.buckets[ClassGlobal]; ok {
+		buckets = append(buckets, b)
+	}
+	if class := classify(path); class != ClassGlobal {
+		if b, ok := l.buckets[class]; ok {
+			buckets = append(buckets, b)
+		}
+	}
+	return buckets
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if burst := float64(b.limit.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket holding tokens is no longer in
+// debt and no longer paused.
+func (b *tokenBucket) wait(now time.Time, tokens float64) time.Duration {
+	var d time.Duration
+	if tokens < 0 {
+		d = time.Duration(-tokens / b.rate * float64(time.Second))
+	}
+	if b.blockedUntil.After(now) {
+		if pause := b.blockedUntil.Sub(now); pause > d {
+			d = pause
+		}
+	}
+	return d
+}
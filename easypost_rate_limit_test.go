@@ -0,0 +1,173 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// fakeClock drives a RateLimiter without real sleeps.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) install(l *RateLimiter) {
+	l.now = func() time.Time { return c.now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		c.slept += d
+		c.now = c.now.Add(d)
+		return nil
+	}
+}
+
+func newTestLimiter(global Limit) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(Limit{})
+	clock.install(l)
+	l.SetLimit(ClassGlobal, global)
+	return l, clock
+}
+
+func TestRateLimiterBurstAndRate(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 10, Burst: 5})
+	for i := 0; i < 5; i++ {
+		l.Wait(context.Background(), "/shipments")
+	}
+	if clock.slept != 0 {
+		t.Fatalf("burst slept %v", clock.slept)
+	}
+	if d := l.Delay("/shipments"); d != 100*time.Millisecond {
+		t.Errorf("Delay = %v, want 100ms", d)
+	}
+	for i := 0; i < 10; i++ {
+		l.Wait(context.Background(), "/shipments")
+	}
+	if clock.slept != time.Second {
+		t.Errorf("10 requests past the burst slept %v, want 1s", clock.slept)
+	}
+}
+
+func TestRateLimiterEndpointClass(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 100, Burst: 100})
+	l.SetLimit(ClassBuy, Limit{Rate: 1, Burst: 1})
+	l.Wait(context.Background(), "/shipments/shp_1/buy")
+	l.Wait(context.Background(), "/shipments/shp_2/buy")
+	if clock.slept != time.Second {
+		t.Errorf("second purchase slept %v, want 1s", clock.slept)
+	}
+	if d := l.Delay("/addresses"); d != 0 {
+		t.Errorf("other endpoints delayed %v by purchases", d)
+	}
+	for path, want := range map[string]string{
+		"/shipments/shp_1/buy":    ClassBuy,
+		"/shipments/shp_1/insure": ClassBuy,
+		"/batches/batch_1":        ClassBatch,
+		"/shipments/shp_1":        ClassGlobal,
+	} {
+		if got := EndpointClass(path); got != want {
+			t.Errorf("EndpointClass(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRateLimiterSlowsDownOn429(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 8, Burst: 1})
+	if !l.Observe("/shipments", http.StatusTooManyRequests, "2") {
+		t.Fatal("429 not reported as throttled")
+	}
+	state := l.State()[0]
+	if state.Rate != 4 || state.Throttled != 1 ||
+		!state.BlockedUntil.Equal(clock.now.Add(2*time.Second)) {
+		t.Errorf("after 429 got %+v", state)
+	}
+	if d := l.Delay("/shipments"); d != 2*time.Second {
+		t.Errorf("Delay = %v, want the 2s Retry-After", d)
+	}
+	for i := 0; i < 5; i++ {
+		l.Observe("/shipments", http.StatusTooManyRequests, "")
+	}
+	if rate := l.State()[0].Rate; rate != 0.5 {
+		t.Errorf("rate fell to %v, want floor of 0.5", rate)
+	}
+	for i := 0; i < 40; i++ {
+		l.Observe("/shipments", http.StatusOK, "")
+	}
+	if rate := l.State()[0].Rate; rate != 8 {
+		t.Errorf("rate recovered to %v, want 8", rate)
+	}
+}
+
+func TestRateLimiterRetriesApiCall(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":"adr_1"}`))
+	}))
+	defer server.Close()
+	defer func(key, base string, limiter *RateLimiter) {
+		EasyPostApi["Key"], EasyPostApi["BaseUrl"], Limiter = key, base, limiter
+	}(EasyPostApi["Key"], EasyPostApi["BaseUrl"], Limiter)
+	EasyPostApi["Key"], EasyPostApi["BaseUrl"] = "test", server.URL
+
+	l, clock := newTestLimiter(Limit{Rate: 10, Burst: 10})
+	Limiter = l
+	response, err := apiCall("/addresses", url.Values{"address[city]": {"X"}})
+	if err != nil || string(response) != `{"id":"adr_1"}` || requests != 3 {
+		t.Fatalf("got %q, %v after %d requests", response, err, requests)
+	}
+	if clock.slept < 2*time.Second {
+		t.Errorf("retries slept only %v", clock.slept)
+	}
+
+	l.MaxRetries = 0
+	requests = 0
+	if _, err = apiCall("/addresses", url.Values{"address[city]": {"X"}}); err == nil {
+		t.Error("expected an error for an unretried 429")
+	} else if apiErr, ok := err.(*APIError); !ok || apiErr.Code != "RATE_LIMITED" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	defer func(key, base string, limiter *RateLimiter) {
+		EasyPostApi["Key"], EasyPostApi["BaseUrl"], Limiter = key, base, limiter
+	}(EasyPostApi["Key"], EasyPostApi["BaseUrl"], Limiter)
+	EasyPostApi["Key"], EasyPostApi["BaseUrl"] = "test", server.URL
+	Limiter = NewRateLimiter(Limit{Rate: 10, Burst: 10})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := apiCallContext(ctx, "/addresses", url.Values{"address[city]": {"X"}})
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled call waited %v for the Retry-After", elapsed)
+	}
+
+	// The cancelled wait gives its token back.
+	before := Limiter.State()[0].Tokens
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err = Limiter.Wait(ctx, "/addresses"); err != context.Canceled {
+		t.Errorf("Wait returned %v", err)
+	}
+	if after := Limiter.State()[0].Tokens; after < before {
+		t.Errorf("tokens fell from %v to %v", before, after)
+	}
+}
@@ -0,0 +1,297 @@
+package easypost
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNoRate is returned when none of a shipment's rates satisfy a RatePolicy.
+var ErrNoRate = errors.New("no rate satisfies the rate policy")
+
+// RateFilter reports whether a rate may be chosen.
+type RateFilter func(rate Rate) bool
+
+// RateOrder compares two rates. It returns a negative number when a should be
+// preferred over b, a positive number when b should be preferred, and zero
+// when it has no preference.
+type RateOrder func(a, b Rate) int
+
+// RatePolicy chooses between the rates returned for a shipment. Every filter
+// must accept a rate for it to be considered. The remaining rates are ranked
+// by Order; the first comparison that separates two rates decides which comes
+// first, and rates it cannot separate keep the order the API returned them in.
+// A policy without any Order ranks by LowestCost.
+type RatePolicy struct {
+	Filters []RateFilter
+	Order   []RateOrder
+}
+
+// Then returns a policy that applies the filters of both policies and ranks
+// by p's order before falling back to next's.
+func (p RatePolicy) Then(next RatePolicy) RatePolicy {
+	var combined RatePolicy
+	combined.Filters = append(combined.Filters, p.Filters...)
+	combined.Filters = append(combined.Filters, next.Filters...)
+	combined.Order = append(combined.Order, p.Order...)
+	combined.Order = append(combined.Order, next.Order...)
+	return combined
+}
+
+// Rank returns the rates accepted by the policy, best first. The rates slice
+// passed in is left untouched.
+func (p RatePolicy) Rank(rates []Rate) []Rate {
+	ranked := make([]Rate, 0, len(rates))
+	for _, rate := range rates {
+		if p.accepts(rate) {
+			ranked = append(ranked, rate)
+		}
+	}
+	order := p.Order
+	if len(order) == 0 {
+		order = []RateOrder{LowestCost}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		for _, compare := range order {
+			if c := compare(ranked[i], ranked[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return ranked
+}
+
+// Select returns the best rate under the policy along with every accepted
+// rate in ranked order. ErrNoRate is returned if no rate is accepted.
+func (p RatePolicy) Select(rates []Rate) (best Rate, ranked []Rate, err error) {
+	ranked = p.Rank(rates)
+	if len(ranked) == 0 {
+		return best, ranked, ErrNoRate
+	}
+	return ranked[0], ranked, nil
+}
+
+func (p RatePolicy) accepts(rate Rate) bool {
+	for _, filter := range p.Filters {
+		if !filter(rate) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateAndBuyBest creates the shipment, selects a rate with policy and buys
+// the label for it, returning the purchased shipment. If no rate is
+// acceptable the created shipment is returned with ErrNoRate so that it can
+// be inspected or rated again.
+func CreateAndBuyBest(shipment *Shipment, policy RatePolicy) (
+	newShipment Shipment, err error) {
+	newShipment, err = NewShipment(shipment)
+	if err != nil {
+		return newShipment, err
+	}
+	best, _, err := policy.Select(newShipment.Rates)
+	if err != nil {
+		return newShipment, err
+	}
+	bought, err := BuyShippingLabel(newShipment.Id, best.Id)
+	if err != nil {
+		return newShipment, err
+	}
+	return bought, nil
+}
+
+// LowestCost prefers cheaper rates. Rates without a price are ranked after
+// those that have one; a price of 0.00 is the cheapest there is.
+func LowestCost(a, b Rate) int {
+	aMissing, bMissing := !a.Rate.Valid(), !b.Rate.Valid()
+	switch {
+	case aMissing && bMissing:
+		return 0
+	case aMissing:
+		return 1
+	case bMissing:
+		return -1
+	}
+	return a.Rate.Cmp(b.Rate)
+}
+
+// FastestDelivery prefers rates with fewer delivery days, falling back to the
+// estimated delivery days when the carrier doesn't commit to a number. Rates
+// that report neither are ranked after those that do.
+func FastestDelivery(a, b Rate) int {
+	aDays, bDays := rateDeliveryDays(a), rateDeliveryDays(b)
+	switch {
+	case aDays == bDays:
+		return 0
+	case aDays == 0:
+		return 1
+	case bDays == 0:
+		return -1
+	}
+	return aDays - bDays
+}
+
+// PreferCarriers prefers rates from the given carriers, earlier carriers
+// first, over rates from any other carrier.
+func PreferCarriers(carriers ...string) RateOrder {
+	return func(a, b Rate) int {
+		return indexFold(carriers, a.Carrier, len(carriers)) -
+			indexFold(carriers, b.Carrier, len(carriers))
+	}
+}
+
+// AllowCarriers only accepts rates from the given carriers.
+func AllowCarriers(carriers ...string) RateFilter {
+	return func(rate Rate) bool {
+		return indexFold(carriers, rate.Carrier, -1) >= 0
+	}
+}
+
+// DenyCarriers rejects rates from the given carriers.
+func DenyCarriers(carriers ...string) RateFilter {
+	return func(rate Rate) bool {
+		return indexFold(carriers, rate.Carrier, -1) < 0
+	}
+}
+
+// AllowServices only accepts rates for the given services, named as the API
+// names them (e.g. "Priority", "FEDEX_GROUND").
+func AllowServices(services ...string) RateFilter {
+	return func(rate Rate) bool {
+		return indexFold(services, rate.Service, -1) >= 0
+	}
+}
+
+// DenyServices rejects rates for the given services.
+func DenyServices(services ...string) RateFilter {
+	return func(rate Rate) bool {
+		return indexFold(services, rate.Service, -1) < 0
+	}
+}
+
+// MaxPrice rejects rates that cost more than max, along with rates quoted in
+// a different currency and, as LowestCost ranks them last, rates without a
+// price.
+func MaxPrice(max Money) RateFilter {
+	return func(rate Rate) bool {
+		if !rate.Rate.Valid() {
+			return false
+		}
+		if max.Currency != "" && rate.Rate.Currency != "" &&
+			!strings.EqualFold(max.Currency, rate.Rate.Currency) {
+			return false
+		}
+		return rate.Rate.Cmp(max) <= 0
+	}
+}
+
+// DeliverBy only accepts rates expected to arrive no later than deadline.
+// The rate's delivery date is used when the carrier supplies one, otherwise
+// its delivery days are counted as business days after now, as
+// SelectSmartRate counts them. Rates with neither are rejected.
+func DeliverBy(now, deadline time.Time) RateFilter {
+	available := businessDaysUntil(now, deadline)
+	return func(rate Rate) bool {
+		if !rate.DeliveryDate.IsZero() {
+			return !rate.DeliveryDate.After(deadline)
+		}
+		days := rateDeliveryDays(rate)
+		return days != 0 && days <= available
+	}
+}
+
+// Percentile selects one of the confidence levels reported in TimeInTransit.
+type Percentile int
+
+const (
+	Percentile50 Percentile = 50
+	Percentile75 Percentile = 75
+	Percentile85 Percentile = 85
+	Percentile90 Percentile = 90
+	Percentile95 Percentile = 95
+	Percentile97 Percentile = 97
+	Percentile99 Percentile = 99
+)
+
+// Days returns the number of business days within which the given percentile
+// of packages were delivered. Zero means the carrier has no data for the
+// service or the percentile isn't one of the reported levels.
+func (t TimeInTransit) Days(percentile Percentile) int {
+	switch percentile {
+	case Percentile50:
+		return t.Percentile50
+	case Percentile75:
+		return t.Percentile75
+	case Percentile85:
+		return t.Percentile85
+	case Percentile90:
+		return t.Percentile90
+	case Percentile95:
+		return t.Percentile95
+	case Percentile97:
+		return t.Percentile97
+	case Percentile99:
+		return t.Percentile99
+	}
+	return 0
+}
+
+// SelectSmartRate returns the cheapest of the smart rates that historically
+// arrived by deadline at least the given percentile of the time, counting
+// business days after now. ErrNoRate is returned if no service qualifies.
+func SelectSmartRate(smartRates []SmartRate, now, deadline time.Time,
+	percentile Percentile) (best SmartRate, err error) {
+	available := businessDaysUntil(now, deadline)
+	found := false
+	for _, smartRate := range smartRates {
+		days := smartRate.TimeInTransit.Days(percentile)
+		if days == 0 || days > available {
+			continue
+		}
+		if !found || LowestCost(smartRate.Rate, best.Rate) < 0 {
+			best = smartRate
+			found = true
+		}
+	}
+	if !found {
+		return best, ErrNoRate
+	}
+	return best, nil
+}
+
+// businessDaysUntil counts the weekdays after from up to and including the
+// day of deadline.
+func businessDaysUntil(from, deadline time.Time) int {
+	days := 0
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0,
+		from.Location())
+	for day := from.AddDate(0, 0, 1); !day.After(deadline); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// rateDeliveryDays returns the carrier's delivery days for a rate, or its
+// estimate if it doesn't give a firm number.
+func rateDeliveryDays(rate Rate) int {
+	if rate.DeliveryDays != 0 {
+		return rate.DeliveryDays
+	}
+	return rate.EstDeliveryDays
+}
+
+// indexFold returns the index of the first entry of list equal to s under
+// case folding, or missing if there is none.
+func indexFold(list []string, s string, missing int) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return missing
+}
@@ -0,0 +1,131 @@
+package easypost
+
+import (
+	"testing"
+	"time"
+)
+
+var testRates = []Rate{
+	{Id: "rate_1", Carrier: "USPS", Service: "Priority", Rate: usd(725), DeliveryDays: 2},
+	{Id: "rate_2", Carrier: "USPS", Service: "ParcelSelect", Rate: usd(610), DeliveryDays: 5},
+	{Id: "rate_3", Carrier: "UPS", Service: "Ground", Rate: usd(980), DeliveryDays: 3},
+	{Id: "rate_4", Carrier: "FedEx", Service: "PRIORITY_OVERNIGHT", Rate: usd(4100), DeliveryDays: 1},
+	{Id: "rate_5", Carrier: "FedEx", Service: "FEDEX_GROUND", Rate: usd(980)},
+}
+
+func rateIds(rates []Rate) []string {
+	ids := make([]string, len(rates))
+	for i, rate := range rates {
+		ids[i] = rate.Id
+	}
+	return ids
+}
+
+func TestRatePolicyLowestCost(t *testing.T) {
+	best, ranked, err := RatePolicy{}.Select(testRates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.Id != "rate_2" {
+		t.Fatal("expected cheapest rate, got " + best.Id)
+	}
+	// rate_3 and rate_5 cost the same and must keep their original order.
+	want := []string{"rate_2", "rate_1", "rate_3", "rate_5", "rate_4"}
+	got := rateIds(ranked)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranked %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRatePolicyFastest(t *testing.T) {
+	policy := RatePolicy{Order: []RateOrder{FastestDelivery, LowestCost}}
+	ranked := policy.Rank(testRates)
+	if ranked[0].Id != "rate_4" || ranked[len(ranked)-1].Id != "rate_5" {
+		t.Fatalf("unexpected ranking %v", rateIds(ranked))
+	}
+}
+
+func TestRatePolicyFilters(t *testing.T) {
+	policy := RatePolicy{Filters: []RateFilter{
+		DenyCarriers("usps"),
+		MaxPrice(usd(1000)),
+	}}.Then(RatePolicy{Order: []RateOrder{PreferCarriers("FedEx")}})
+	got := rateIds(policy.Rank(testRates))
+	if len(got) != 2 || got[0] != "rate_5" || got[1] != "rate_3" {
+		t.Fatalf("unexpected ranking %v", got)
+	}
+
+	_, _, err := RatePolicy{Filters: []RateFilter{
+		AllowServices("Express"),
+	}}.Select(testRates)
+	if err != ErrNoRate {
+		t.Fatal("expected ErrNoRate")
+	}
+}
+
+func TestRatePolicyMissingPrice(t *testing.T) {
+	rates := append([]Rate{{Id: "rate_0", Carrier: "USPS", Service: "Express"}},
+		testRates...)
+	ranked := RatePolicy{}.Rank(rates)
+	if ranked[0].Id != "rate_2" || ranked[len(ranked)-1].Id != "rate_0" {
+		t.Fatalf("unexpected ranking %v", rateIds(ranked))
+	}
+	if MaxPrice(usd(1000))(rates[0]) {
+		t.Error("MaxPrice accepted a rate without a price")
+	}
+
+	// A price of 0.00 is a price, and the lowest one.
+	free := Rate{Id: "rate_free", Rate: usd(0)}
+	ranked = RatePolicy{}.Rank(append(rates, free))
+	if ranked[0].Id != "rate_free" || !MaxPrice(usd(1000))(free) {
+		t.Fatalf("unexpected ranking of a free rate %v", rateIds(ranked))
+	}
+}
+
+func TestRatePolicyDeliverBy(t *testing.T) {
+	// From a Friday to the next Tuesday is two business days, although it
+	// is four calendar days.
+	friday := time.Date(2024, time.June, 7, 15, 0, 0, 0, time.UTC)
+	deadline := time.Date(2024, time.June, 11, 18, 0, 0, 0, time.UTC)
+	got := rateIds(RatePolicy{Filters: []RateFilter{
+		DeliverBy(friday, deadline),
+	}}.Rank(testRates))
+	if len(got) != 2 || got[0] != "rate_1" || got[1] != "rate_4" {
+		t.Fatalf("unexpected rates %v", got)
+	}
+
+	dated := Rate{Id: "rate_6", DeliveryDays: 9,
+		DeliveryDate: time.Date(2024, time.June, 10, 12, 0, 0, 0, time.UTC)}
+	if !DeliverBy(friday, deadline)(dated) {
+		t.Fatal("expected the delivery date to take precedence over delivery days")
+	}
+}
+
+func TestSelectSmartRate(t *testing.T) {
+	smartRates := []SmartRate{
+		{Rate: Rate{Id: "rate_1", Rate: usd(725)},
+			TimeInTransit: TimeInTransit{Percentile50: 2, Percentile95: 4}},
+		{Rate: Rate{Id: "rate_2", Rate: usd(610)},
+			TimeInTransit: TimeInTransit{Percentile50: 3, Percentile95: 8}},
+		{Rate: Rate{Id: "rate_3", Rate: usd(3000)},
+			TimeInTransit: TimeInTransit{Percentile50: 1, Percentile95: 1}},
+	}
+	monday := time.Date(2024, time.June, 3, 9, 0, 0, 0, time.UTC)
+	// Two full weeks contain ten business days and one week five.
+	deadline := monday.AddDate(0, 0, 14)
+	best, err := SelectSmartRate(smartRates, monday, deadline, Percentile95)
+	if err != nil || best.Id != "rate_2" {
+		t.Fatalf("got %s, %v", best.Id, err)
+	}
+	deadline = monday.AddDate(0, 0, 7)
+	best, err = SelectSmartRate(smartRates, monday, deadline, Percentile95)
+	if err != nil || best.Id != "rate_1" {
+		t.Fatalf("got %s, %v", best.Id, err)
+	}
+	_, err = SelectSmartRate(smartRates, monday, monday, Percentile50)
+	if err != ErrNoRate {
+		t.Fatal("expected ErrNoRate for a deadline of today")
+	}
+}
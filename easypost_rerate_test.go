@@ -0,0 +1,79 @@
+package easypost
+
+import (
+	"net/http"
+	"testing"
+)
+
+const testRatesResponse = `{"rates":[
+	{"id":"rate_1","carrier":"UPS","service":"Ground","rate":"9.80","currency":"USD"},
+	{"id":"rate_2","carrier":"USPS","service":"Priority","rate":"7.25","currency":"USD"}]}`
+
+func TestRerateShipment(t *testing.T) {
+	var method, path string
+	var accounts []string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		accounts = readForm(r)["carrier_accounts[]"]
+		w.Write([]byte(testRatesResponse))
+	})()
+
+	rates, err := RerateShipment("shp_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "POST" || path != "/shipments/shp_1/rerate" {
+		t.Errorf("sent %s %s", method, path)
+	}
+	if len(rates) != 2 || rates[0].ServiceName != "UPS Ground" ||
+		rates[1].Rate.Cmp(usd(725)) != 0 {
+		t.Errorf("got %+v", rates)
+	}
+
+	if _, err = RerateShipment("shp_1", "ca_1", "ca_2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0] != "ca_1" || accounts[1] != "ca_2" {
+		t.Errorf("sent carrier accounts %q", accounts)
+	}
+}
+
+func TestQuoteRates(t *testing.T) {
+	var path string
+	var form map[string][]string
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		path, form = r.URL.Path, readForm(r)
+		w.Write([]byte(testRatesResponse))
+	})()
+
+	shipment := Shipment{
+		ToAddress:   Address{Id: "adr_to"},
+		FromAddress: Address{Id: "adr_from"},
+		Parcel:      Parcel{Length: Inches(10), Width: Inches(8), Height: Inches(4), Weight: Ounces(16)},
+	}
+	rates, err := QuoteRates(&shipment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/beta/rates" || form["shipment[parcel][weight]"][0] != "16" {
+		t.Errorf("sent %s %v", path, form)
+	}
+	if len(rates) != 2 {
+		t.Errorf("got %+v", rates)
+	}
+
+	if _, err = QuoteRates(&Shipment{}); err == nil {
+		t.Error("expected preflight to reject a shipment without a parcel")
+	}
+}
+
+func TestApiUrl(t *testing.T) {
+	defer func(base string) { EasyPostApi["BaseUrl"] = base }(EasyPostApi["BaseUrl"])
+	EasyPostApi["BaseUrl"] = "https://api.easypost.com/v2"
+	if got := apiUrl("/beta/rates"); got != "https://api.easypost.com/beta/rates" {
+		t.Errorf("beta endpoint at %s", got)
+	}
+	if got := apiUrl("/shipments"); got != "https://api.easypost.com/v2/shipments" {
+		t.Errorf("endpoint at %s", got)
+	}
+}
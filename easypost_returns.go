@@ -0,0 +1,89 @@
+package easypost
+
+import (
+	"strings"
+	"sync"
+)
+
+var payOnUseMu sync.RWMutex
+
+// payOnUseCarriers are the carriers whose return labels are only charged
+// when the carrier scans them, keyed by upper case name.
+var payOnUseCarriers = map[string]bool{
+	"USPS": true,
+}
+
+// RegisterPayOnUseCarrier adds a carrier whose return labels are billed when
+// they are scanned, for PayOnUse to accept. USPS is registered already.
+func RegisterPayOnUseCarrier(carrier string) {
+	payOnUseMu.Lock()
+	defer payOnUseMu.Unlock()
+	payOnUseCarriers[strings.ToUpper(carrier)] = true
+}
+
+// ReturnOptions adjusts the return shipment made by CreateReturnShipment.
+type ReturnOptions struct {
+	// PayOnUse keeps only the rates of carriers that bill a return label
+	// when it is scanned rather than when it is bought, so that labels
+	// sent to customers who never use them cost nothing.
+	PayOnUse bool
+	// Reference replaces the original shipment's reference.
+	Reference string
+}
+
+// CreateReturnShipment creates a return for original, reusing its addresses,
+// parcel and customs declaration. The addresses are sent as they were on the
+// original: with is_return set, the API swaps them itself, so the returned
+// shipment goes from original.ToAddress back to original.FromAddress. Buy
+// one of its rates with BuyShippingLabel.
+func CreateReturnShipment(original *Shipment, options ReturnOptions) (
+	returnShipment Shipment, err error) {
+	shipment := Shipment{
+		ToAddress:   original.ToAddress,
+		FromAddress: original.FromAddress,
+		Parcel:      original.Parcel,
+		CustomsInfo: original.CustomsInfo,
+		Reference:   original.Reference,
+		IsReturn:    true,
+	}
+	if options.Reference != "" {
+		shipment.Reference = options.Reference
+	}
+	returnShipment, err = NewShipment(&shipment)
+	if err == nil && options.PayOnUse {
+		returnShipment.Rates = payOnUseRates(returnShipment.Rates)
+		if len(returnShipment.Rates) == 0 {
+			err = ErrNoRate
+		}
+	}
+	return returnShipment, err
+}
+
+// CreateReturnShipmentById retrieves the shipment and creates a return for it
+// with CreateReturnShipment.
+func CreateReturnShipmentById(shipmentId string, options ReturnOptions) (
+	returnShipment Shipment, err error) {
+	original, err := RetrieveShipment(shipmentId)
+	if err != nil {
+		return returnShipment, err
+	}
+	return CreateReturnShipment(&original, options)
+}
+
+// PayOnUse accepts only rates from carriers that bill return labels when
+// they are scanned: USPS and those added with RegisterPayOnUseCarrier.
+func PayOnUse(rate Rate) bool {
+	payOnUseMu.RLock()
+	defer payOnUseMu.RUnlock()
+	return payOnUseCarriers[strings.ToUpper(rate.Carrier)]
+}
+
+func payOnUseRates(rates []Rate) []Rate {
+	var kept []Rate
+	for _, rate := range rates {
+		if PayOnUse(rate) {
+			kept = append(kept, rate)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,99 @@
+package easypost
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestReturnShipmentRequest(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.Write([]byte(`{"id":"shp_ret","is_return":true,"rates":[
+			{"id":"rate_1","carrier":"USPS","service":"Priority","rate":"7.25"},
+			{"id":"rate_2","carrier":"UPS","service":"Ground","rate":"9.80"}]}`))
+	}))
+	defer server.Close()
+	defer func(key, base string) {
+		EasyPostApi["Key"], EasyPostApi["BaseUrl"] = key, base
+	}(EasyPostApi["Key"], EasyPostApi["BaseUrl"])
+	EasyPostApi["Key"], EasyPostApi["BaseUrl"] = "test", server.URL
+
+	original := Shipment{
+		Id:          "shp_1",
+		ToAddress:   Address{Id: "adr_customer"},
+		FromAddress: Address{Id: "adr_warehouse"},
+		Parcel:      Parcel{Id: "prcl_1"},
+		CustomsInfo: CustomsInfo{Id: "cstinfo_1"},
+		Reference:   "order-1",
+	}
+	returnShipment, err := CreateReturnShipment(&original, ReturnOptions{PayOnUse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"shipment[is_return]":        "true",
+		"shipment[to_address][id]":   "adr_customer",
+		"shipment[from_address][id]": "adr_warehouse",
+		"shipment[parcel][id]":       "prcl_1",
+		"shipment[customs_info][id]": "cstinfo_1",
+		"shipment[reference]":        "order-1",
+	} {
+		if got := form[key]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if !returnShipment.IsReturn || len(returnShipment.Rates) != 1 ||
+		returnShipment.Rates[0].Carrier != "USPS" {
+		t.Errorf("got %+v", returnShipment)
+	}
+}
+
+func TestReturnShipmentById(t *testing.T) {
+	var requests []string
+	var form url.Values
+	defer useTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/shipments/shp_1":
+			w.Write([]byte(`{"id":"shp_1","reference":"order-1",
+				"to_address":{"id":"adr_customer"},"from_address":{"id":"adr_warehouse"},
+				"parcel":{"id":"prcl_1"}}`))
+		case "/shipments":
+			form = readForm(r)
+			w.Write([]byte(`{"id":"shp_ret","is_return":true,"rates":[
+				{"id":"rate_1","carrier":"USPS","service":"Priority","rate":"7.25"},
+				{"id":"rate_2","carrier":"Acme","service":"Return","rate":"4.00"},
+				{"id":"rate_3","carrier":"UPS","service":"Ground","rate":"9.80"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})()
+	RegisterPayOnUseCarrier("acme")
+	t.Cleanup(func() {
+		payOnUseMu.Lock()
+		defer payOnUseMu.Unlock()
+		delete(payOnUseCarriers, "ACME")
+	})
+
+	returnShipment, err := CreateReturnShipmentById("shp_1",
+		ReturnOptions{PayOnUse: true, Reference: "rma-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0] != "GET /shipments/shp_1" ||
+		requests[1] != "POST /shipments" {
+		t.Errorf("sent %q", requests)
+	}
+	if form.Get("shipment[to_address][id]") != "adr_customer" ||
+		form.Get("shipment[reference]") != "rma-1" || form.Get("shipment[is_return]") != "true" {
+		t.Errorf("sent %v", form)
+	}
+	if len(returnShipment.Rates) != 2 || returnShipment.Rates[1].Carrier != "Acme" {
+		t.Errorf("kept rates %v", rateIds(returnShipment.Rates))
+	}
+}
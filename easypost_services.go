@@ -0,0 +1,128 @@
+package easypost
+
+import (
+	"strings"
+	"sync"
+)
+
+// ServiceLevel groups carrier services by speed.
+type ServiceLevel string
+
+const (
+	LevelEconomy   ServiceLevel = "economy"
+	LevelGround    ServiceLevel = "ground"
+	LevelThreeDay  ServiceLevel = "3-day"
+	LevelTwoDay    ServiceLevel = "2-day"
+	LevelOvernight ServiceLevel = "overnight"
+)
+
+// ServiceInfo describes one carrier service. Carrier and Service are named
+// as the API names them on a Rate. TransitDays is the carrier's typical time
+// in transit in business days, for display and rough planning only; use
+// SmartRates for anything that matters.
+type ServiceInfo struct {
+	Carrier       string
+	Service       string
+	DisplayName   string
+	Level         ServiceLevel
+	International bool
+	TransitDays   int
+}
+
+var servicesMu sync.RWMutex
+
+var services = map[string]ServiceInfo{}
+
+func init() {
+	for _, info := range []ServiceInfo{
+		{"USPS", "First", "USPS First-Class Package", LevelGround, false, 3},
+		{"USPS", "GroundAdvantage", "USPS Ground Advantage", LevelGround, false, 3},
+		{"USPS", "Priority", "USPS Priority Mail", LevelTwoDay, false, 2},
+		{"USPS", "Express", "USPS Priority Mail Express", LevelOvernight, false, 1},
+		{"USPS", "ParcelSelect", "USPS Parcel Select", LevelEconomy, false, 5},
+		{"USPS", "LibraryMail", "USPS Library Mail", LevelEconomy, false, 7},
+		{"USPS", "MediaMail", "USPS Media Mail", LevelEconomy, false, 7},
+		{"USPS", "CriticalMail", "USPS Critical Mail", LevelTwoDay, false, 2},
+		{"USPS", "FirstClassMailInternational", "USPS First-Class Mail International", LevelEconomy, true, 10},
+		{"USPS", "FirstClassPackageInternationalService", "USPS First-Class Package International Service", LevelEconomy, true, 10},
+		{"USPS", "PriorityMailInternational", "USPS Priority Mail International", LevelGround, true, 7},
+		{"USPS", "ExpressMailInternational", "USPS Priority Mail Express International", LevelThreeDay, true, 4},
+
+		{"UPS", "Ground", "UPS Ground", LevelGround, false, 3},
+		{"UPS", "3DaySelect", "UPS 3 Day Select", LevelThreeDay, false, 3},
+		{"UPS", "2ndDayAir", "UPS 2nd Day Air", LevelTwoDay, false, 2},
+		{"UPS", "2ndDayAirAM", "UPS 2nd Day Air A.M.", LevelTwoDay, false, 2},
+		{"UPS", "NextDayAir", "UPS Next Day Air", LevelOvernight, false, 1},
+		{"UPS", "NextDayAirSaver", "UPS Next Day Air Saver", LevelOvernight, false, 1},
+		{"UPS", "NextDayAirEarlyAM", "UPS Next Day Air Early", LevelOvernight, false, 1},
+		{"UPS", "UPSStandard", "UPS Standard", LevelGround, true, 5},
+		{"UPS", "Expedited", "UPS Worldwide Expedited", LevelThreeDay, true, 4},
+		{"UPS", "UPSSaver", "UPS Worldwide Saver", LevelTwoDay, true, 2},
+		{"UPS", "Express", "UPS Worldwide Express", LevelOvernight, true, 2},
+		{"UPS", "ExpressPlus", "UPS Worldwide Express Plus", LevelOvernight, true, 1},
+
+		{"FedEx", "FEDEX_GROUND", "FedEx Ground", LevelGround, false, 3},
+		{"FedEx", "GROUND_HOME_DELIVERY", "FedEx Home Delivery", LevelGround, false, 3},
+		{"FedEx", "SMART_POST", "FedEx SmartPost", LevelEconomy, false, 5},
+		{"FedEx", "FEDEX_EXPRESS_SAVER", "FedEx Express Saver", LevelThreeDay, false, 3},
+		{"FedEx", "FEDEX_2_DAY", "FedEx 2Day", LevelTwoDay, false, 2},
+		{"FedEx", "FEDEX_2_DAY_AM", "FedEx 2Day A.M.", LevelTwoDay, false, 2},
+		{"FedEx", "STANDARD_OVERNIGHT", "FedEx Standard Overnight", LevelOvernight, false, 1},
+		{"FedEx", "PRIORITY_OVERNIGHT", "FedEx Priority Overnight", LevelOvernight, false, 1},
+		{"FedEx", "FIRST_OVERNIGHT", "FedEx First Overnight", LevelOvernight, false, 1},
+		{"FedEx", "INTERNATIONAL_ECONOMY", "FedEx International Economy", LevelGround, true, 5},
+		{"FedEx", "INTERNATIONAL_PRIORITY", "FedEx International Priority", LevelTwoDay, true, 3},
+		{"FedEx", "INTERNATIONAL_FIRST", "FedEx International First", LevelOvernight, true, 2},
+	} {
+		RegisterService(info)
+	}
+}
+
+func serviceKey(carrier string, service string) string {
+	return strings.ToUpper(carrier) + "\x00" + service
+}
+
+// RegisterService adds a service to the catalog, or replaces the entry for
+// the same carrier and service. Use it for carriers the library doesn't know
+// or to change how a service is displayed. Rates decoded afterwards pick up
+// the new entry.
+func RegisterService(info ServiceInfo) {
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+	services[serviceKey(info.Carrier, info.Service)] = info
+}
+
+// LookupService returns the catalog entry for a carrier's service. Carrier
+// names are matched without regard to case; service names must match exactly.
+func LookupService(carrier string, service string) (info ServiceInfo, ok bool) {
+	servicesMu.RLock()
+	defer servicesMu.RUnlock()
+	info, ok = services[serviceKey(carrier, service)]
+	return info, ok
+}
+
+// ServiceName returns the display name of a carrier's service, or the service
+// name itself if it isn't in the catalog.
+func ServiceName(carrier string, service string) string {
+	if info, ok := LookupService(carrier, service); ok {
+		return info.DisplayName
+	}
+	return service
+}
+
+// AllowServiceLevels only accepts rates for services at the given levels.
+// Services missing from the catalog are rejected.
+func AllowServiceLevels(levels ...ServiceLevel) RateFilter {
+	return func(rate Rate) bool {
+		info, ok := LookupService(rate.Carrier, rate.Service)
+		if !ok {
+			return false
+		}
+		for _, level := range levels {
+			if info.Level == level {
+				return true
+			}
+		}
+		return false
+	}
+}
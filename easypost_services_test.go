@@ -0,0 +1,39 @@
+package easypost
+
+import "testing"
+
+func TestServiceCatalog(t *testing.T) {
+	var shipment Shipment
+	err := handleJson([]byte(`{"rates": [
+		{"carrier": "UPS", "service": "Ground", "rate": "9.80"},
+		{"carrier": "FedEx", "service": "FEDEX_GROUND", "rate": "9.75"},
+		{"carrier": "FedEx", "service": "INTERNATIONAL_PRIORITY", "rate": "80.00"},
+		{"carrier": "Acme", "service": "Rocket", "rate": "99.00"}]}`), &shipment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"UPS Ground", "FedEx Ground", "FedEx International Priority", "Rocket"}
+	for i, name := range names {
+		if shipment.Rates[i].ServiceName != name {
+			t.Fatalf("rate %d named %q, want %q", i, shipment.Rates[i].ServiceName, name)
+		}
+	}
+	info, ok := LookupService("fedex", "INTERNATIONAL_PRIORITY")
+	if !ok || !info.International || info.Level != LevelTwoDay {
+		t.Fatalf("unexpected service %+v", info)
+	}
+
+	RegisterService(ServiceInfo{Carrier: "Acme", Service: "Rocket",
+		DisplayName: "Acme Rocket", Level: LevelOvernight, TransitDays: 1})
+	t.Cleanup(func() {
+		servicesMu.Lock()
+		defer servicesMu.Unlock()
+		delete(services, serviceKey("Acme", "Rocket"))
+	})
+	ranked := RatePolicy{Filters: []RateFilter{
+		AllowServiceLevels(LevelOvernight),
+	}}.Rank(shipment.Rates)
+	if len(ranked) != 1 || ranked[0].Carrier != "Acme" {
+		t.Fatalf("unexpected rates %v", rateIds(ranked))
+	}
+}
@@ -0,0 +1,203 @@
+package easypost
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// Mode is whether an object was made with a test or a production API key.
+type Mode string
+
+const (
+	ModeTest       Mode = "test"
+	ModeProduction Mode = "production"
+)
+
+// TrackingStatus is where a package is in its journey, as reported on
+// trackers, their details and shipments.
+type TrackingStatus string
+
+const (
+	TrackingUnknown            TrackingStatus = "unknown"
+	TrackingPreTransit         TrackingStatus = "pre_transit"
+	TrackingInTransit          TrackingStatus = "in_transit"
+	TrackingOutForDelivery     TrackingStatus = "out_for_delivery"
+	TrackingDelivered          TrackingStatus = "delivered"
+	TrackingAvailableForPickup TrackingStatus = "available_for_pickup"
+	TrackingReturnToSender     TrackingStatus = "return_to_sender"
+	TrackingFailure            TrackingStatus = "failure"
+	TrackingCancelled          TrackingStatus = "cancelled"
+	TrackingError              TrackingStatus = "error"
+)
+
+// Final reports whether the package will not move any further.
+func (s TrackingStatus) Final() bool {
+	switch s {
+	case TrackingDelivered, TrackingReturnToSender, TrackingFailure,
+		TrackingCancelled:
+		return true
+	}
+	return false
+}
+
+// RefundStatus is the progress of a label refund.
+type RefundStatus string
+
+const (
+	RefundSubmitted     RefundStatus = "submitted"
+	RefundRefunded      RefundStatus = "refunded"
+	RefundRejected      RefundStatus = "rejected"
+	RefundNotApplicable RefundStatus = "not_applicable"
+)
+
+// BatchState is the progress of a batch through creation, purchase and
+// label generation.
+type BatchState string
+
+const (
+	BatchCreating        BatchState = "creating"
+	BatchCreationFailed  BatchState = "creation_failed"
+	BatchCreated         BatchState = "created"
+	BatchPurchasing      BatchState = "purchasing"
+	BatchPurchaseFailed  BatchState = "purchase_failed"
+	BatchPurchased       BatchState = "purchased"
+	BatchLabelGenerating BatchState = "label_generating"
+	BatchLabelGenerated  BatchState = "label_generated"
+)
+
+// ScanFormStatus is the progress of a scan form.
+type ScanFormStatus string
+
+const (
+	ScanFormCreating ScanFormStatus = "creating"
+	ScanFormCreated  ScanFormStatus = "created"
+	ScanFormFailed   ScanFormStatus = "failed"
+)
+
+// copyRaw keeps a copy of the JSON an object was decoded from; the decoder
+// may reuse the buffer once UnmarshalJSON returns. A null object keeps no
+// JSON.
+func copyRaw(data []byte) json.RawMessage {
+	if string(data) == "null" {
+		return nil
+	}
+	return append(json.RawMessage(nil), data...)
+}
+
+// RawField returns a field of an object's Raw JSON, following nested
+// objects through path, e.g. RawField(shipment.Raw, "options", "hazmat").
+// Every object decoded from the API keeps the JSON it came from in Raw, so
+// attributes added to the API after this library was released can still be
+// read, and are written back when the object is encoded again.
+func RawField(raw json.RawMessage, path ...string) (value json.RawMessage, ok bool) {
+	value = raw
+	for _, key := range path {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(value, &fields) != nil {
+			return nil, false
+		}
+		if value, ok = fields[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, len(value) > 0
+}
+
+// zeroTimeJSON is how encoding/json writes a zero time.Time.
+const zeroTimeJSON = `"0001-01-01T00:00:00Z"`
+
+// marshalModel encodes an object decoded from the API, given value, the
+// object converted to a type without a MarshalJSON method, and original, a
+// pointer to a new object of the decoded type. Fields the API sent that the
+// object doesn't know of are kept from raw, as are known fields left
+// unchanged since decoding, so that an object decoded and encoded again
+// reads as it came. Zero times and empty objects, including objects that
+// are entirely zero, are left out.
+func marshalModel(value interface{}, raw json.RawMessage, original interface{}) (
+	[]byte, error) {
+	if raw == nil && reflect.ValueOf(value).IsZero() {
+		return []byte("{}"), nil
+	}
+	current, err := marshalClean(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if raw == nil || json.Unmarshal(raw, &fields) != nil {
+		return json.Marshal(current)
+	}
+	if err = json.Unmarshal(raw, original); err != nil {
+		return nil, err
+	}
+	decoded := reflect.ValueOf(original).Elem().Convert(reflect.TypeOf(value))
+	unchanged, err := marshalClean(decoded.Interface())
+	if err != nil {
+		return nil, err
+	}
+	for key := range unchanged {
+		if _, ok := current[key]; !ok {
+			delete(fields, key)
+		}
+	}
+	for key, after := range current {
+		if string(unchanged[key]) != string(after) {
+			fields[key] = after
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// marshalClean encodes value as an object and splits it into its fields,
+// leaving out those that are null, zero times or empty objects at any depth.
+func marshalClean(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	clean := map[string]json.RawMessage{}
+	for key, field := range fields {
+		if field = cleanJSON(field); field != nil {
+			if clean[key], err = json.Marshal(field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return clean, nil
+}
+
+// cleanJSON returns value without the null, zero time and empty object
+// fields of the objects in it, or nil if value itself is one of those.
+func cleanJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if field = cleanJSON(field); field == nil {
+				delete(value, key)
+			} else {
+				value[key] = field
+			}
+		}
+		if len(value) == 0 {
+			return nil
+		}
+	case []interface{}:
+		for i, element := range value {
+			if element = cleanJSON(element); element != nil {
+				value[i] = element
+			}
+		}
+	case string:
+		if strconv.Quote(value) == zeroTimeJSON {
+			return nil
+		}
+	}
+	return value
+}
@@ -63,10 +63,10 @@ func TestRetrieveAddress(t *testing.T) {
 
 func TestNewParcel(t *testing.T) {
 	parcel, err := NewParcel(&Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	})
 	if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("creating test parcel failed")
@@ -75,10 +75,10 @@ func TestNewParcel(t *testing.T) {
 
 func TestRetrieveParcel(t *testing.T) {
 	parcel, err := NewParcel(&Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	})
 	if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("parcel couldn't be retrieved because it couldn't be created")
@@ -127,10 +127,10 @@ func TestNewShipment(t *testing.T) {
 	}
 
 	parcel, err := NewParcel(&Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	})
 	if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("creating parcel for shipment failed")
@@ -175,10 +175,10 @@ func TestRetrieveRates(t *testing.T) {
 	}
 
 	parcel, err := NewParcel(&Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	})
 	if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("creating parcel to get rates failed")
@@ -228,10 +228,10 @@ func TestRetrieveShipment(t *testing.T) {
 	}
 
 	parcel, err := NewParcel(&Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	})
 	if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("creating parcel for shipment retrieval failed")
@@ -292,10 +292,10 @@ func TestBuyShippingLabel(t *testing.T) {
 	}*/
 
 	parcel := Parcel{
-		Length: 17.5,
-		Width:  12,
-		Height: 15.5,
-		Weight: 124,
+		Length: Inches(17.5),
+		Width:  Inches(12),
+		Height: Inches(15.5),
+		Weight: Ounces(124),
 	}
 	/*if !HasPrefix(parcel.Id, "prcl_") || err != nil {
 		t.Fatal("creating parcel to buy shipment failed")
@@ -315,7 +315,7 @@ func TestBuyShippingLabel(t *testing.T) {
 		t.Fatal("getting rates failed")
 	}
 	label, err := BuyShippingLabel(shipment.Id, rates[0].Id)
-	if label.Object != "PostageLabel" || err != nil {
+	if label.PostageLabel.Object != "PostageLabel" || err != nil {
 		t.Log(label)
 		t.Log(shipment)
 		t.Log(shipment.Id)
@@ -358,10 +358,10 @@ func TestNewRefund(t *testing.T) {
   }
 
   parcel, err := NewParcel(&Parcel{
-    Length: 17.5,
-    Width: 12,
-    Height: 15.5,
-    Weight: 124,
+    Length: Inches(17.5),
+    Width: Inches(12),
+    Height: Inches(15.5),
+    Weight: Ounces(124),
     })
   if !HasPrefix(parcel.Id, "prcl_") || err != nil {
     t.Fatal("creating parcel to buy shipment failed")
@@ -381,7 +381,7 @@ func TestNewRefund(t *testing.T) {
     t.Fatal("getting rates failed")
   }
   label, err := BuyShippingLabel(shipment.Id, rates[0].Id)
-  if label.Object != "PostageLabel" || err != nil {
+  if label.PostageLabel.Object != "PostageLabel" || err != nil {
     t.Log(label)
     t.Log(shipment)
     t.Log(shipment.Id)
@@ -407,7 +407,7 @@ func TestNewCustomsItem(t *testing.T){
     Description: "It's bananas",
     Quantity: 144,
     Value: "84",
-    Weight: 900,
+    Weight: Ounces(900),
     HsTariffNumber: "194723",
     OriginCountry: "US",
     })
@@ -425,7 +425,7 @@ func TestRetrieveCustomsItems(t *testing.T) {
     Description: "It's bananas",
     Quantity: 144,
     Value: "84",
-    Weight: 900,
+    Weight: Ounces(900),
     HsTariffNumber: "194723",
     OriginCountry: "US",
     })
@@ -456,7 +456,7 @@ func TestNewCustomsInfo(t *testing.T) {
     Description: "It's bananas",
     Quantity: 144,
     Value: "84",
-    Weight: 900,
+    Weight: Ounces(900),
     HsTariffNumber: "194723",
     OriginCountry: "US",
     })
@@ -464,7 +464,7 @@ func TestNewCustomsInfo(t *testing.T) {
     Description: "It's apples",
     Quantity: 16,
     Value: "3.50",
-    Weight: 25,
+    Weight: Ounces(25),
     HsTariffNumber: "235163",
     OriginCountry: "US",
     })
@@ -494,7 +494,7 @@ func TestRetrieveCustomsInfo(t *testing.T) {
     Description: "It's bananas",
     Quantity: 144,
     Value: "84",
-    Weight: 900,
+    Weight: Ounces(900),
     HsTariffNumber: "194723",
     OriginCountry: "US",
     })
@@ -502,7 +502,7 @@ func TestRetrieveCustomsInfo(t *testing.T) {
     Description: "It's apples",
     Quantity: 16,
     Value: "3.50",
-    Weight: 25,
+    Weight: Ounces(25),
     HsTariffNumber: "235163",
     OriginCountry: "US",
     })
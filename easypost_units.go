@@ -0,0 +1,203 @@
+package easypost
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// Weight is a mass. Build it with Ounces, Pounds, Grams or Kilograms, e.g.
+// Parcel{Weight: Kilograms(2.5)}; a bare number can't be assigned to it, so
+// the unit is always written down. The zero Weight is zero.
+//
+// In JSON a Weight is a number of ounces, the unit the API uses.
+type Weight struct {
+	oz float64
+}
+
+// Length is a distance. Build it with Inches, Centimeters or Millimeters,
+// e.g. Parcel{Length: Centimeters(30)}. The zero Length is zero.
+//
+// In JSON a Length is a number of inches, the unit the API uses.
+type Length struct {
+	in float64
+}
+
+const (
+	gramsPerOunce      = 28.349523125
+	ouncesPerPound     = 16
+	centimetersPerInch = 2.54
+)
+
+func Ounces(oz float64) Weight {
+	return Weight{oz}
+}
+
+func Pounds(lb float64) Weight {
+	return Weight{lb * ouncesPerPound}
+}
+
+func Grams(g float64) Weight {
+	return Weight{g / gramsPerOunce}
+}
+
+func Kilograms(kg float64) Weight {
+	return Grams(kg * 1000)
+}
+
+func (w Weight) Ounces() float64 {
+	return w.oz
+}
+
+func (w Weight) Pounds() float64 {
+	return w.oz / ouncesPerPound
+}
+
+func (w Weight) Grams() float64 {
+	return w.oz * gramsPerOunce
+}
+
+func (w Weight) Kilograms() float64 {
+	return w.Grams() / 1000
+}
+
+// IsZero reports whether the weight is zero.
+func (w Weight) IsZero() bool {
+	return w.oz == 0
+}
+
+// Add returns w + o.
+func (w Weight) Add(o Weight) Weight {
+	return Weight{w.oz + o.oz}
+}
+
+// Mul returns w multiplied by n, e.g. a unit weight times a quantity.
+func (w Weight) Mul(n float64) Weight {
+	return Weight{w.oz * n}
+}
+
+func (w Weight) MarshalJSON() ([]byte, error) {
+	return marshalUnits(w.oz)
+}
+
+// UnmarshalJSON accepts a number of ounces, or a string holding one. null
+// and "" decode to zero.
+func (w *Weight) UnmarshalJSON(data []byte) error {
+	return unmarshalUnits(data, &w.oz)
+}
+
+func Inches(in float64) Length {
+	return Length{in}
+}
+
+func Centimeters(cm float64) Length {
+	return Length{cm / centimetersPerInch}
+}
+
+func Millimeters(mm float64) Length {
+	return Centimeters(mm / 10)
+}
+
+func (l Length) Inches() float64 {
+	return l.in
+}
+
+func (l Length) Centimeters() float64 {
+	return l.in * centimetersPerInch
+}
+
+func (l Length) Millimeters() float64 {
+	return l.Centimeters() * 10
+}
+
+// IsZero reports whether the length is zero.
+func (l Length) IsZero() bool {
+	return l.in == 0
+}
+
+// Add returns l + o.
+func (l Length) Add(o Length) Length {
+	return Length{l.in + o.in}
+}
+
+// Mul returns l multiplied by n.
+func (l Length) Mul(n float64) Length {
+	return Length{l.in * n}
+}
+
+func (l Length) MarshalJSON() ([]byte, error) {
+	return marshalUnits(l.in)
+}
+
+// UnmarshalJSON accepts a number of inches, or a string holding one. null
+// and "" decode to zero.
+func (l *Length) UnmarshalJSON(data []byte) error {
+	return unmarshalUnits(data, &l.in)
+}
+
+func marshalUnits(v float64) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, errors.New("easypost: can't encode " +
+			strconv.FormatFloat(v, 'g', -1, 64) + " in JSON")
+	}
+	return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+}
+
+func unmarshalUnits(data []byte, v *float64) error {
+	text := string(bytes.TrimSpace(data))
+	if len(text) > 0 && text[0] == '"' {
+		var err error
+		if text, err = strconv.Unquote(text); err != nil {
+			return err
+		}
+	}
+	if text == "null" || text == "" {
+		*v = 0
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// formatUnits formats a weight or length for a request, rounded to four
+// decimal places so that converted metric values don't carry noise digits.
+func formatUnits(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
+
+// checkPositive records a FieldError if v isn't a positive, finite number.
+func checkPositive(errs *ValidationErrors, field string, v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v <= 0 {
+		*errs = append(*errs, FieldError{Field: field,
+			Message: "must be greater than zero"})
+	}
+}
+
+// Validate checks the parcel locally before it is sent to the API. Weight
+// must always be positive, as must each dimension, except that a predefined
+// package, whose size the carrier already knows, may leave its dimensions
+// out. A predefined package must be in the catalog; see
+// RegisterPredefinedPackage.
+func (parc *Parcel) Validate() error {
+	var errs ValidationErrors
+	dimensions := []struct {
+		field string
+		value Length
+	}{{"length", parc.Length}, {"width", parc.Width}, {"height", parc.Height}}
+	for _, dimension := range dimensions {
+		if parc.PredefinedPackage == "" || !dimension.value.IsZero() {
+			checkPositive(&errs, dimension.field, dimension.value.Inches())
+		}
+	}
+	if parc.PredefinedPackage != "" && !isPredefinedPackage(parc.PredefinedPackage) {
+		errs = append(errs, FieldError{Field: "predefined_package",
+			Message: "unknown predefined package " + parc.PredefinedPackage})
+	}
+	checkPositive(&errs, "weight", parc.Weight.Ounces())
+	return errs.err()
+}
@@ -0,0 +1,105 @@
+package easypost
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestUnitConversions(t *testing.T) {
+	if math.Abs(Kilograms(1).Pounds()-2.20462) > 1e-5 {
+		t.Fatal("a kilogram should be about 2.2 pounds")
+	}
+	if Pounds(2).Ounces() != 32 {
+		t.Fatal("two pounds should be 32 ounces")
+	}
+	if formatUnits(Centimeters(2.54).Inches()) != "1" ||
+		formatUnits(Millimeters(100).Inches()) != "3.937" {
+		t.Fatal("length conversion is off")
+	}
+	if formatUnits(Grams(500).Ounces()) != "17.637" {
+		t.Fatal("500g encoded as " + formatUnits(Grams(500).Ounces()))
+	}
+}
+
+func TestUnitsJSON(t *testing.T) {
+	var parcel Parcel
+	err := json.Unmarshal([]byte(`{"length": 10.5, "width": "8", "height": null,
+		"weight": 16}`), &parcel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parcel.Length != Inches(10.5) || parcel.Width != Inches(8) ||
+		!parcel.Height.IsZero() || parcel.Weight.Pounds() != 1 {
+		t.Fatalf("decoded %+v", parcel)
+	}
+	encoded, err := json.Marshal(Pounds(1.5))
+	if err != nil || string(encoded) != "24" {
+		t.Fatalf("encoded %s, %v", encoded, err)
+	}
+}
+
+func TestParcelValidate(t *testing.T) {
+	parcel := Parcel{Length: Centimeters(30), Width: Inches(12), Height: Inches(-1)}
+	err := parcel.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 2 || errs[0].Field != "height" ||
+		errs[1].Field != "weight" {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+	parcel = Parcel{PredefinedPackage: "FlatRateEnvelope", Weight: Grams(200)}
+	if err := parcel.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// Validation failures must be caught before any request is made.
+	_, err = NewShipment(&Shipment{Parcel: Parcel{Weight: Ounces(10)}})
+	if errs, ok := err.(ValidationErrors); !ok || errs[0].Field != "parcel.length" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPredefinedPackages(t *testing.T) {
+	parcel := USPSMediumFlatRateBox.Parcel(Pounds(3))
+	if err := parcel.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := parcel.ValidateForCarriers("USPS", "UPS"); err == nil {
+		t.Fatal("UPS doesn't offer USPS flat rate boxes")
+	}
+	if len(PredefinedPackages("fedex")) == 0 {
+		t.Fatal("expected FedEx packages in the catalog")
+	}
+	shipment := Shipment{Parcel: parcel}
+	errs, ok := shipment.Preflight("UPS").(ValidationErrors)
+	if !ok || len(errs) != 1 || errs[0].Field != "parcel.predefined_package" {
+		t.Fatalf("unexpected preflight errors: %v", errs)
+	}
+	if err := shipment.Preflight("usps"); err != nil {
+		t.Fatal(err)
+	}
+	shipment.Carriers = []string{"UPS"}
+	if _, err := NewShipment(&shipment); err == nil || err.Error() != errs.Error() {
+		t.Fatalf("NewShipment didn't check the package against Carriers: %v", err)
+	}
+	if _, err := QuoteRates(&shipment); err == nil || err.Error() != errs.Error() {
+		t.Fatalf("QuoteRates didn't check the package against Carriers: %v", err)
+	}
+	parcel.Height = Inches(-2)
+	if err := parcel.Validate(); err == nil {
+		t.Fatal("negative dimensions should be rejected for predefined packages")
+	}
+	parcel.Height = Length{}
+	parcel.PredefinedPackage = "MysteryBox"
+	if err := parcel.Validate(); err == nil {
+		t.Fatal("unknown packages should be rejected")
+	}
+
+	data := url.Values{}
+	encodeParcel(data, "parcel", &Parcel{PredefinedPackage: "Pak", Weight: Ounces(8)})
+	if data.Get("parcel[predefined_package]") != "Pak" || data.Get("parcel[length]") != "16" ||
+		data.Get("parcel[width]") != "12.75" || data.Get("parcel[weight]") != "8" ||
+		len(data) != 4 {
+		t.Fatalf("encoded %v", data)
+	}
+}
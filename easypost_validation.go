@@ -0,0 +1,59 @@
+package easypost
+
+import (
+	"strings"
+)
+
+// FieldError describes a problem with a single field of an object, found
+// either locally or by the API. Code and Suggestion are only filled in by the
+// API.
+type FieldError struct {
+	Code       string `json:"code,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func (e FieldError) Error() string {
+	message := e.Field + ": " + e.Message
+	if e.Suggestion != "" {
+		message += " (did you mean " + e.Suggestion + "?)"
+	}
+	return message
+}
+
+// ValidationErrors is returned when an object fails local validation. It
+// lists every problem found rather than only the first.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// withPrefix adds prefix to each field name of a ValidationErrors, for
+// reporting problems in nested objects. Other errors are returned unchanged.
+func withPrefix(err error, prefix string) error {
+	v, ok := err.(ValidationErrors)
+	if !ok {
+		return err
+	}
+	errs := make(ValidationErrors, len(v))
+	for i, e := range v {
+		e.Field = prefix + e.Field
+		errs[i] = e
+	}
+	return errs
+}
+
+// err returns v as an error, or nil if there are no errors. Returning a nil
+// ValidationErrors directly would give a non-nil error interface.
+func (v ValidationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}